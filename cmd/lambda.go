@@ -32,6 +32,7 @@ func cmdLambda() *cobra.Command {
 	cmd.AddCommand(
 		cmdLambdaHTTP(),
 		cmdLambdaEvent(),
+		cmdLambdaSchedule(),
 	)
 
 	bindEnvMap(cmd, lambdaEnvMapString)
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/spf13/cobra"
+)
+
+func cmdLambdaSchedule() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "schedule",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger.Info("lambda starting...")
+			lambda.StartWithOptions(promotionRuntime.LambdaForSchedule,
+				lambda.WithContext(cmd.Context()))
+			return nil
+		},
+	}
+
+	bindEnvMap(cmd, reconcileEnvMapStringSlice)
+
+	return cmd
+}
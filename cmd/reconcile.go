@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/handler"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func cmdReconcile() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "reconcile",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger.Debug("creating promotion handler...")
+			hdl, err := handler.NewPromotionHandler(
+				handler.WithAuthMode(config.GitHub.AuthMode),
+				handler.WithSSMKey(config.GitHub.SSMKey),
+				handler.WithToken(os.Getenv("GITHUB_TOKEN")),
+				handler.WithContext(cmd.Context()),
+				handler.WithLogger(logger))
+			if err != nil {
+				return errors.Wrap(err, "failed to create promotion handler")
+			}
+
+			logger.Info("reconciling...")
+			report, err := hdl.Reconcile(cmd.Context())
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			if encodeErr := encoder.Encode(report); encodeErr != nil {
+				logger.Error("failed to encode the reconciliation report", slog.Any("error", encodeErr))
+			}
+			return err
+		},
+	}
+
+	bindEnvMap(cmd, reconcileEnvMapStringSlice)
+
+	return cmd
+}
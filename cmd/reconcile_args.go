@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	"github.com/isometry/gh-promotion-app/internal/config"
+)
+
+var reconcileEnvMapStringSlice = map[*[]string]boundEnvVar[[]string]{
+	&config.Reconcile.Repositories: {
+		Name:        "reconcile-repositories",
+		Description: "Restrict the reconciliation to the given repositories (owner/name). Required with the 'token' auth mode",
+	},
+}
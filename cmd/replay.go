@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/aws"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler"
+	"github.com/isometry/gh-promotion-app/internal/replay"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func cmdReplay() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "replay [path...]",
+		RunE: func(cmd *cobra.Command, paths []string) error {
+			filter, err := replayFilter()
+			if err != nil {
+				return err
+			}
+			bucket := cmp.Or(config.Replay.BucketName, config.Global.S3.Upload.BucketName)
+			if len(paths) == 0 && bucket == "" {
+				return errors.New("no payload source: pass payload files or directories, or set the replay bucket")
+			}
+
+			// Replayed payloads are processed regardless of the recorded outcome of their delivery, and not archived again
+			config.Idempotency.Enabled = false
+			config.Global.S3.Upload.Enabled = false
+
+			logger.Debug("creating promotion handler...")
+			hdl, err := handler.NewPromotionHandler(
+				handler.WithAuthMode(config.GitHub.AuthMode),
+				handler.WithSSMKey(config.GitHub.SSMKey),
+				handler.WithToken(os.Getenv("GITHUB_TOKEN")),
+				handler.WithoutSignatureValidation(),
+				handler.WithContext(cmd.Context()),
+				handler.WithLogger(logger))
+			if err != nil {
+				return errors.Wrap(err, "failed to create promotion handler")
+			}
+
+			var payloads []replay.Payload
+			if len(paths) > 0 {
+				payloads, err = replay.FromPaths(paths, event.Type(config.Replay.EventType), filter)
+			} else {
+				var awsCtl *aws.Controller
+				if awsCtl, err = aws.NewController(
+					aws.WithLogger(logger.With("component", "aws-controller")),
+					aws.WithContext(cmd.Context())); err != nil {
+					return errors.Wrap(err, "failed to create AWS controller")
+				}
+				payloads, err = replay.FromS3(cmd.Context(), awsCtl, bucket, config.Replay.Prefix, filter)
+			}
+			if err != nil {
+				return err
+			}
+			replay.Sort(payloads)
+
+			logger.Info("replaying payloads...", slog.Int("payloads", len(payloads)), slog.Bool("dryRun", config.Global.DryRun))
+			results, err := replay.Replay(cmd.Context(), hdl.Process, payloads)
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			for _, result := range results {
+				if encodeErr := encoder.Encode(result); encodeErr != nil {
+					logger.Error("failed to encode the replay result", slog.Any("error", encodeErr))
+				}
+			}
+			return err
+		},
+	}
+
+	bindEnvMap(cmd, replayEnvMapString)
+	bindEnvMap(cmd, replayEnvMapStringSlice)
+
+	return cmd
+}
+
+// replayFilter returns the filter of the replayed payloads configured by config.Replay.
+func replayFilter() (filter replay.Filter, err error) {
+	filter.Repositories = config.Replay.Repositories
+	filter.EventTypes = config.Replay.EventTypes
+	if config.Replay.Since != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, config.Replay.Since); err != nil {
+			return filter, errors.Wrap(err, "invalid replay-since time")
+		}
+	}
+	if config.Replay.Until != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, config.Replay.Until); err != nil {
+			return filter, errors.Wrap(err, "invalid replay-until time")
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+)
+
+var replayEnvMapString = map[*string]boundEnvVar[string]{
+	&config.Replay.EventType: {
+		Name:        "replay-event-type",
+		Description: "The event type of the replayed payload files. Required for files not named after the keys of the S3 uploader",
+		Short:       helpers.Ptr("e"),
+	},
+	&config.Replay.BucketName: {
+		Name:        "replay-bucket",
+		Description: "The S3 bucket to replay the archived payloads from if no path is given (defaults to the S3 upload bucket)",
+	},
+	&config.Replay.Prefix: {
+		Name:        "replay-prefix",
+		Description: "Restrict the replayed S3 objects to the keys starting with this prefix, e.g. '2024-03-16'",
+	},
+	&config.Replay.Since: {
+		Name:        "replay-since",
+		Description: "Restrict the replay to the payloads archived at or after this RFC 3339 time",
+	},
+	&config.Replay.Until: {
+		Name:        "replay-until",
+		Description: "Restrict the replay to the payloads archived at or before this RFC 3339 time",
+	},
+}
+
+var replayEnvMapStringSlice = map[*[]string]boundEnvVar[[]string]{
+	&config.Replay.Repositories: {
+		Name:        "replay-repositories",
+		Description: "Restrict the replay to the payloads of the given repositories (owner/name)",
+	},
+	&config.Replay.EventTypes: {
+		Name:        "replay-event-types",
+		Description: "Restrict the replay to the payloads of the given event types",
+	},
+}
@@ -42,6 +42,8 @@ func New() *cobra.Command {
 				cmd.SetArgs([]string{"lambda", "http"})
 			case config.ModeLambdaEvent:
 				cmd.SetArgs([]string{"lambda", "event"})
+			case config.ModeLambdaSchedule:
+				cmd.SetArgs([]string{"lambda", "schedule"})
 			default:
 				return fmt.Errorf("invalid mode: %s", config.Global.Mode)
 			}
@@ -67,6 +69,8 @@ func New() *cobra.Command {
 	cmd.AddCommand(
 		cmdLambda(),
 		cmdService(),
+		cmdReconcile(),
+		cmdReplay(),
 	)
 
 	return cmd
@@ -8,7 +8,7 @@ import (
 var envMapString = map[*string]boundEnvVar[string]{
 	&config.Global.Mode: {
 		Name:        "mode",
-		Description: "The application runtime mode. Possible values are 'lambda-event', 'lambda-http' and 'service'",
+		Description: "The application runtime mode. Possible values are 'lambda-event', 'lambda-http', 'lambda-schedule' and 'service'",
 		Short:       helpers.Ptr("m"),
 	},
 	&config.GitHub.AuthMode: {
@@ -29,13 +29,21 @@ var envMapString = map[*string]boundEnvVar[string]{
 		Description: "The key to use when fetching the dynamic promoter configuration",
 		Env:         helpers.Ptr("DYNAMIC_PROMOTION_KEY"),
 	},
+	&config.Promotion.DefaultGraph: {
+		Name:        "promotion-default-graph",
+		Description: "The default promotion graph, e.g. 'main > staging > canary-eu, canary-us > production'. Takes precedence over the default stages",
+	},
 	&config.Promotion.Feedback.CommitStatus.Context: {
 		Name:        "feedback-commit-status-context",
-		Description: "The context key to use when pushing the commit status to the repository. Supported placeholders: {source}, {target}",
+		Description: "The context key to use when pushing the commit status to the repository. Supported placeholders: {source}, {target}, {progress}",
 	},
 	&config.Promotion.Feedback.CheckRun.Name: {
 		Name:        "feedback-check-run-name",
-		Description: "The name to use when creating the check run. Supported placeholders: {source}, {target}",
+		Description: "The name to use when creating the check run. Supported placeholders: {source}, {target}, {progress}",
+	},
+	&config.Idempotency.Store: {
+		Name:        "idempotency-store",
+		Description: "The idempotency store backend. Supported values are 'memory', 'file' and 's3'",
 	},
 	&config.Global.S3.Upload.BucketName: {
 		Name:        "promotion-report-s3-upload-bucket",
@@ -45,6 +53,10 @@ var envMapString = map[*string]boundEnvVar[string]{
 }
 
 var envMapBool = map[*bool]boundEnvVar[bool]{
+	&config.Global.DryRun: {
+		Name:        "dry-run",
+		Description: "Record the GitHub mutations that would be performed and return them in the response instead of executing them",
+	},
 	&config.Global.Logging.CallerTrace: {
 		Name:        "verbosity-caller-trace",
 		Description: "Enable caller trace in logs",
@@ -72,6 +84,14 @@ var envMapBool = map[*bool]boundEnvVar[bool]{
 		Description: "Enable S3 upload of promotion reports",
 		Env:         helpers.Ptr("PROMOTION_REPORT_S3_UPLOAD"),
 	},
+	&config.Idempotency.Enabled: {
+		Name:        "idempotency",
+		Description: "Answer webhook redeliveries with the outcome recorded for their delivery ID",
+	},
+	&config.Idempotency.ForceReprocess: {
+		Name:        "force-reprocess",
+		Description: "Process webhook redeliveries again, ignoring their recorded outcome",
+	},
 }
 
 var envMapCount = map[*int]boundEnvVar[int]{
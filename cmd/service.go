@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/isometry/gh-promotion-app/internal/config"
 	"github.com/isometry/gh-promotion-app/internal/handler"
@@ -30,8 +34,14 @@ func cmdService() *cobra.Command {
 				return err
 			}
 			logger.Debug("creating runtime...")
-			runtime := runtime.NewRuntime(hdl,
-				runtime.WithLogger(logger.With("component", "runtime")))
+			runtimeOpts := []runtime.Option{
+				runtime.WithLogger(logger.With("component", "runtime")),
+				runtime.WithRequestTimeout(config.Service.Timeout),
+			}
+			if async := config.Service.Async; async.Enabled {
+				runtimeOpts = append(runtimeOpts, runtime.WithAsync(async.Workers, async.QueueSize, async.JobTimeout))
+			}
+			runtime := runtime.NewRuntime(hdl, runtimeOpts...)
 
 			h := http.NewServeMux()
 			h.HandleFunc(config.Service.Path, runtime.Service)
@@ -44,14 +54,36 @@ func cmdService() *cobra.Command {
 				IdleTimeout:  config.Service.Timeout,
 			}
 
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
 			logger.Info("service starting...",
 				slog.String("service", fmt.Sprintf("%+v", config.Service)),
 				slog.String("authMode", config.GitHub.AuthMode))
-			return s.ListenAndServe()
+			serveErr := make(chan error, 1)
+			go func() {
+				serveErr <- s.ListenAndServe()
+			}()
+
+			select {
+			case err = <-serveErr:
+				return err
+			case <-ctx.Done():
+			}
+
+			// Stop accepting requests, then complete the queued work
+			logger.Info("service stopping...")
+			drainCtx, cancel := context.WithTimeout(context.Background(), config.Service.Async.DrainTimeout)
+			defer cancel()
+			if err = s.Shutdown(drainCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("failed to shutdown the service", slog.Any("error", err))
+			}
+			return runtime.Shutdown(drainCtx)
 		},
 	}
 
 	bindEnvMap(cmd, svcEnvMapString)
+	bindEnvMap(cmd, svcEnvMapBool)
 	bindEnvMap(cmd, svcEnvMapDuration)
 
 	return cmd
@@ -25,6 +25,13 @@ var svcEnvMapString = map[*string]boundEnvVar[string]{
 	},
 }
 
+var svcEnvMapBool = map[*bool]boundEnvVar[bool]{
+	&config.Service.Async.Enabled: {
+		Name:        "async",
+		Description: "Answer authenticated requests with 202 Accepted and process them in a background worker pool",
+	},
+}
+
 var svcEnvMapDuration = map[*time.Duration]boundEnvVar[time.Duration]{
 	&config.Service.Timeout: {
 		Name:        "service-io-timeout",
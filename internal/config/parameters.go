@@ -23,6 +23,14 @@ var (
 	Service service
 	// Lambda is a struct that contains the configuration for the lambda mode.
 	Lambda lambda
+	// Pipeline is a struct that contains the processor pipeline composition.
+	Pipeline pipeline
+	// Idempotency is a struct that contains the configuration for webhook delivery deduplication.
+	Idempotency idempotency
+	// Reconcile is a struct that contains the configuration for the reconciliation of missed webhooks.
+	Reconcile reconcile
+	// Replay is a struct that contains the configuration for the replay of stored webhook payloads.
+	Replay replay
 )
 
 const (
@@ -32,11 +40,27 @@ const (
 	ModeLambdaHTTP = "lambda-http"
 	// ModeLambdaEvent is the lambda-event runtime mode.
 	ModeLambdaEvent = "lambda-event"
+	// ModeLambdaSchedule is the lambda-schedule runtime mode.
+	ModeLambdaSchedule = "lambda-schedule"
+
+	// StoreMemory is the in-memory idempotency store.
+	StoreMemory = "memory"
+	// StoreFile is the file-backed idempotency store.
+	StoreFile = "file"
+	// StoreS3 is the S3-backed idempotency store.
+	StoreS3 = "s3"
+
+	// StrategyFastForward promotes by fast-forwarding the stage branch.
+	StrategyFastForward = "fast-forward"
+	// StrategyMergeQueue promotes by enqueuing the promotion request into the merge queue of the stage branch.
+	StrategyMergeQueue = "merge-queue"
 )
 
 type global struct {
-	// Mode is the runtime mode of the application. (service, lambda-http, lambda-event)
+	// Mode is the runtime mode of the application. (service, lambda-http, lambda-event, lambda-schedule)
 	Mode string `yaml:"mode,omitempty" default:"lambda-http"`
+	// DryRun records the GitHub mutations that would be performed instead of executing them.
+	DryRun bool `yaml:"dryRun,omitempty"`
 	// Logging is a struct that contains the logging configuration.
 	Logging struct {
 		// Verbosity is the verbosity level of the application. It represents slog levels.
@@ -64,8 +88,11 @@ type promotion struct {
 	} `yaml:"dynamicPromotion,omitempty"`
 	// DefaultStages is a slice of default promotion stages.
 	DefaultStages []string `yaml:"defaultStages,omitempty" default:"[\"main\", \"staging\", \"canary\", \"production\"]"`
+	// DefaultGraph is the default promotion graph, e.g. "main > staging > canary-eu, canary-us > production", taking
+	// precedence over DefaultStages if set. Chains are separated by ";", levels by ">" and the stages of a level by ",".
+	DefaultGraph string `yaml:"defaultGraph,omitempty"`
 	// Events is a slice of GitHub webhook events to listen to.
-	Events []string `yaml:"events,omitempty" default:"[\"push\", \"pull_request\", \"pull_request_review\", \"deployment_status\", \"status\", \"check_suite\", \"workflow_run\"]"`
+	Events []string `yaml:"events,omitempty" default:"[\"push\", \"pull_request\", \"pull_request_review\", \"deployment_status\", \"status\", \"check_suite\", \"check_run\", \"merge_group\", \"workflow_run\", \"issue_comment\", \"create\", \"delete\", \"custom_property_values\", \"installation\", \"installation_repositories\"]"`
 	// Push is a struct that contains the configuration for pushing changes.
 	Push struct {
 		// CreatePullRequestInDraftModeKey is the key to use to inspect the repository custom properties for draft PR creation.
@@ -73,6 +100,30 @@ type promotion struct {
 		// CreateTargetRef is a flag that enables the creation of missing target branches.
 		CreateTargetRef bool `yaml:"createTargetRef,omitempty" default:"true"`
 	} `yaml:"push,omitempty"`
+	// Installation configures the handling of app installation events.
+	Installation struct {
+		// Reconcile is a flag that enables opening the promotion requests of repositories added to an installation.
+		Reconcile bool `yaml:"reconcile,omitempty" default:"false"`
+	} `yaml:"installation,omitempty"`
+	// SelfEvents configures the filtering of the status, check_suite and check_run events echoing the feedback of the app.
+	SelfEvents struct {
+		Enabled bool `yaml:"enabled,omitempty" default:"true"`
+		// BotLogin is the login of the bot user of the app, e.g. "my-promotion-app[bot]". Unset only recognises the bot
+		// user of check_suite and check_run events, through the app ID of the credentials.
+		BotLogin string `yaml:"botLogin,omitempty"`
+		// IgnoredSenders lists the logins of additional senders whose events are ignored, e.g. a personal access token user.
+		IgnoredSenders []string `yaml:"ignoredSenders,omitempty"`
+		// IgnoredContexts lists glob patterns of additional commit status contexts to ignore. The commit status feedback
+		// context is always ignored.
+		IgnoredContexts []string `yaml:"ignoredContexts,omitempty"`
+	} `yaml:"selfEvents,omitempty"`
+	// ChatOps configures the slash commands accepted in comments on promotion requests.
+	ChatOps struct {
+		// HoldLabel is the label marking promotion requests held with the hold command.
+		HoldLabel string `yaml:"holdLabel,omitempty" default:"promotion-hold"`
+	} `yaml:"chatOps,omitempty"`
+	// Stages holds per-stage settings, keyed by stage branch name.
+	Stages map[string]Stage `yaml:"stages,omitempty"`
 	// Feedback is a struct that contains the configuration for feedback.
 	Feedback struct {
 		CommitStatus struct {
@@ -86,10 +137,87 @@ type promotion struct {
 	} `yaml:"feedback,omitempty"`
 }
 
+// Stage holds the settings of promotions into a single stage.
+type Stage struct {
+	// Strategy is how promotions into the stage are applied. (fast-forward, merge-queue)
+	Strategy string `yaml:"strategy,omitempty" default:"fast-forward"`
+	// MinRole is the minimum repository role required to issue ChatOps commands on promotions into the stage. (read, triage, write, maintain, admin)
+	MinRole string `yaml:"minRole,omitempty" default:"write"`
+	// DeploymentEnvironment is the deployment environment in which the head SHA must be successfully deployed before it is
+	// promoted into the stage. Deployments to other environments do not trigger promotions into the stage. Unset disables the gate.
+	DeploymentEnvironment string `yaml:"deploymentEnvironment,omitempty"`
+	// RequiredChecks lists the commit status contexts and check run names that must succeed on the head SHA before it is
+	// fast-forwarded into the stage. Unset defaults to the required checks of the branch protection and rulesets of the stage.
+	RequiredChecks []string `yaml:"requiredChecks,omitempty"`
+	// WorkflowRun restricts the workflow runs triggering promotions into the stage.
+	WorkflowRun WorkflowRunFilter `yaml:"workflowRun,omitempty"`
+	// Reviews is the policy the reviews of the promotion request must satisfy for an approval to promote into the stage.
+	Reviews ReviewPolicy `yaml:"reviews,omitempty"`
+}
+
+// ReviewPolicy is the policy the reviews of a promotion request must satisfy for an approval to promote it.
+type ReviewPolicy struct {
+	// MinApprovals is the minimum number of approving reviewers.
+	MinApprovals int `yaml:"minApprovals,omitempty" default:"1"`
+	// RequiredTeams lists the teams, each of which must have a member approving, e.g. "sre" or "my-org/sre".
+	RequiredTeams []string `yaml:"requiredTeams,omitempty"`
+	// DismissStale ignores the approvals of commits other than the head SHA, e.g. approvals preceding new pushes.
+	DismissStale bool `yaml:"dismissStale,omitempty"`
+	// AllowChangesRequested promotes despite reviewers whose latest review requests changes.
+	AllowChangesRequested bool `yaml:"allowChangesRequested,omitempty"`
+}
+
+// WorkflowRunFilter restricts the workflow runs triggering promotions. Empty lists match any workflow run.
+type WorkflowRunFilter struct {
+	// Workflows lists glob patterns matched against the workflow name or path, e.g. ".github/workflows/release-*.yml".
+	Workflows []string `yaml:"workflows,omitempty"`
+	// Events lists the events triggering the workflow run, e.g. push or pull_request.
+	Events []string `yaml:"events,omitempty"`
+	// Actors lists the logins of the users triggering the workflow run.
+	Actors []string `yaml:"actors,omitempty"`
+}
+
+// Stage returns the settings of the given stage branch, falling back to the defaults for unlisted stages.
+func (p promotion) Stage(name string) Stage {
+	stage, found := p.Stages[name]
+	if !found {
+		_ = defaults.Set(&stage)
+	}
+	return stage
+}
+
 type github struct {
 	AuthMode      string `yaml:"authMode,omitempty" default:"ssm"`
 	SSMKey        string `yaml:"ssmKey,omitempty"`
 	WebhookSecret string `yaml:"webhookSecret,omitempty"`
+	// ClientCache bounds the cache of GitHub clients kept per installation.
+	ClientCache struct {
+		// TTL is the duration after which cached clients are discarded. Zero disables expiry.
+		TTL time.Duration `yaml:"ttl,omitempty" default:"1h"`
+		// MaxEntries is the maximum number of cached installations. Zero disables the limit.
+		MaxEntries int `yaml:"maxEntries,omitempty" default:"256"`
+	} `yaml:"clientCache,omitempty"`
+	// Retry bounds the retries of GitHub API calls failing with transient errors.
+	Retry struct {
+		// Default is the policy applied to every operation.
+		Default RetryPolicy `yaml:"default,omitempty"`
+		// Operations overrides the default policy per operation, e.g. "update-ref". Unset fields inherit the default policy.
+		Operations map[string]RetryPolicy `yaml:"operations,omitempty" default:"-"`
+	} `yaml:"retry,omitempty"`
+}
+
+// RetryPolicy bounds the retries of a GitHub API operation.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one. 1 disables retries.
+	MaxAttempts int `yaml:"maxAttempts,omitempty" default:"3"`
+	// InitialBackoff is the backoff before the first retry. It doubles on every retry, with full jitter.
+	InitialBackoff time.Duration `yaml:"initialBackoff,omitempty" default:"200ms"`
+	// MaxBackoff caps the backoff between two attempts.
+	MaxBackoff time.Duration `yaml:"maxBackoff,omitempty" default:"2s"`
+	// Budget bounds the total duration of all attempts.
+	Budget time.Duration `yaml:"budget,omitempty" default:"10s"`
+	// Idempotent overrides whether the operation is safe to repeat, which is otherwise inferred from the HTTP method.
+	Idempotent *bool `yaml:"idempotent,omitempty"`
 }
 
 type service struct {
@@ -97,12 +225,99 @@ type service struct {
 	Addr    string        `yaml:"addr,omitempty"`
 	Port    string        `yaml:"port,omitempty" default:"8080"`
 	Timeout time.Duration `yaml:"timeout,omitempty" default:"5s"`
+	// Async is a struct that contains the configuration for asynchronous processing.
+	Async struct {
+		// Enabled answers authenticated requests with 202 Accepted and processes them in the background.
+		Enabled bool `yaml:"enabled,omitempty"`
+		// Workers is the number of concurrent workers.
+		Workers int `yaml:"workers,omitempty" default:"4"`
+		// QueueSize is the maximum number of queued requests before answering with 503 Service Unavailable.
+		QueueSize int `yaml:"queueSize,omitempty" default:"100"`
+		// JobTimeout bounds the processing of each queued request.
+		JobTimeout time.Duration `yaml:"jobTimeout,omitempty" default:"1m"`
+		// DrainTimeout bounds the time spent completing queued work on shutdown.
+		DrainTimeout time.Duration `yaml:"drainTimeout,omitempty" default:"30s"`
+	} `yaml:"async,omitempty"`
 }
 
 type lambda struct {
 	PayloadType string `yaml:"payloadType,omitempty" default:"api-gateway-v2"`
 }
 
+// PipelineStep references a registered processor by name along with its per-processor options.
+type PipelineStep struct {
+	// Name is the name under which the processor is registered.
+	Name string `yaml:"name"`
+	// Options are the processor-specific options. Unknown options are rejected at startup.
+	Options map[string]any `yaml:"options,omitempty"`
+}
+
+type pipeline struct {
+	// Pre is the ordered list of processors run after authentication and before the event processors.
+	Pre []PipelineStep `yaml:"pre,omitempty" default:"[{\"name\": \"self-event-filter\"}, {\"name\": \"dynamic-promotion\"}]"`
+	// Events maps an event type to its ordered list of processors. Event types left out keep their default processors.
+	Events map[string][]PipelineStep `yaml:"events,omitempty"`
+	// Post is the ordered list of processors run after the event processors.
+	Post []PipelineStep `yaml:"post,omitempty" default:"[{\"name\": \"fast-forwarder\"}, {\"name\": \"s3-uploader\"}]"`
+	// Feedback is the ordered list of processors run last to report the promotion outcome.
+	Feedback []PipelineStep `yaml:"feedback,omitempty" default:"[{\"name\": \"commit-status\"}, {\"name\": \"check-run-feedback\"}, {\"name\": \"chat-ops\"}]"`
+	// Timeouts bounds the duration of each phase. A zero value only inherits the request deadline.
+	Timeouts struct {
+		Auth     time.Duration `yaml:"auth,omitempty"`
+		Pre      time.Duration `yaml:"pre,omitempty"`
+		Event    time.Duration `yaml:"event,omitempty"`
+		Post     time.Duration `yaml:"post,omitempty"`
+		Feedback time.Duration `yaml:"feedback,omitempty"`
+	} `yaml:"timeouts,omitempty"`
+}
+
+type idempotency struct {
+	// Enabled records the outcome of each processed delivery and answers redeliveries with the recorded outcome.
+	Enabled bool `yaml:"enabled,omitempty" default:"true"`
+	// ForceReprocess processes redeliveries again, overwriting their recorded outcome.
+	ForceReprocess bool `yaml:"forceReprocess,omitempty"`
+	// Store is the idempotency store backend. (memory, file, s3)
+	Store string `yaml:"store,omitempty" default:"memory"`
+	// TTL is the duration for which a recorded outcome is honoured.
+	TTL time.Duration `yaml:"ttl,omitempty" default:"72h"`
+	// MaxEntries is the maximum number of deliveries remembered by the memory store.
+	MaxEntries int `yaml:"maxEntries,omitempty" default:"1000"`
+	// Path is the directory used by the file store.
+	Path string `yaml:"path,omitempty" default:".deliveries"`
+	// BucketName is the S3 bucket used by the s3 store.
+	BucketName string `yaml:"bucketName,omitempty"`
+	// Prefix is the S3 key prefix used by the s3 store.
+	Prefix string `yaml:"prefix,omitempty" default:"deliveries/"`
+}
+
+type reconcile struct {
+	// Installations restricts the reconciliation to the given installation IDs. Unset reconciles every installation of
+	// the app, which requires credentials signing with a private key file.
+	Installations []int64 `yaml:"installations,omitempty"`
+	// Repositories restricts the reconciliation to the given repositories, e.g. "my-org/my-repo". It is required in token
+	// auth mode, whose credentials cannot list the repositories of an installation.
+	Repositories []string `yaml:"repositories,omitempty"`
+}
+
+type replay struct {
+	// EventType is the event type of the replayed payload files. It is required for files not named after the keys of
+	// the S3 uploader, and overrides the event type of the others.
+	EventType string `yaml:"eventType,omitempty"`
+	// BucketName is the S3 bucket the payloads archived by the S3 uploader are replayed from, if no path is given.
+	// Defaults to global.s3.upload.bucketName.
+	BucketName string `yaml:"bucketName,omitempty"`
+	// Prefix restricts the replayed objects to the keys starting with it, e.g. "2024-03-16" for the payloads of a day.
+	Prefix string `yaml:"prefix,omitempty"`
+	// Repositories restricts the replay to the payloads of the given repositories, e.g. "my-org/my-repo".
+	Repositories []string `yaml:"repositories,omitempty"`
+	// EventTypes restricts the replay to the payloads of the given event types.
+	EventTypes []string `yaml:"eventTypes,omitempty"`
+	// Since restricts the replay to the payloads archived at or after this RFC 3339 time.
+	Since string `yaml:"since,omitempty"`
+	// Until restricts the replay to the payloads archived at or before this RFC 3339 time.
+	Until string `yaml:"until,omitempty"`
+}
+
 // SetDefaults sets the default values for the configuration.
 func SetDefaults() error {
 	return errors.Join(
@@ -111,6 +326,10 @@ func SetDefaults() error {
 		defaults.Set(&Promotion),
 		defaults.Set(&Service),
 		defaults.Set(&Lambda),
+		defaults.Set(&Pipeline),
+		defaults.Set(&Idempotency),
+		defaults.Set(&Reconcile),
+		defaults.Set(&Replay),
 	)
 }
 
@@ -135,11 +354,15 @@ func LoadFromFile(path string) error {
 		return fmt.Errorf("failed to read configuration file %s: %w", path, err)
 	}
 	type all struct {
-		Global    global    `yaml:"global,omitempty"`
-		Promotion promotion `yaml:"promotion,omitempty"`
-		Service   service   `yaml:"service,omitempty"`
-		Lambda    lambda    `yaml:"lambda,omitempty"`
-		GitHub    github    `yaml:"github,omitempty"`
+		Global      global      `yaml:"global,omitempty"`
+		Promotion   promotion   `yaml:"promotion,omitempty"`
+		Service     service     `yaml:"service,omitempty"`
+		Lambda      lambda      `yaml:"lambda,omitempty"`
+		GitHub      github      `yaml:"github,omitempty"`
+		Pipeline    pipeline    `yaml:"pipeline,omitempty"`
+		Idempotency idempotency `yaml:"idempotency,omitempty"`
+		Reconcile   reconcile   `yaml:"reconcile,omitempty"`
+		Replay      replay      `yaml:"replay,omitempty"`
 	}
 	var a all
 	if err = yaml.Unmarshal(content, &a); err != nil {
@@ -150,6 +373,10 @@ func LoadFromFile(path string) error {
 	Service = a.Service
 	Lambda = a.Lambda
 	GitHub = a.GitHub
+	Pipeline = a.Pipeline
+	Idempotency = a.Idempotency
+	Reconcile = a.Reconcile
+	Replay = a.Replay
 
 	return nil
 }
@@ -5,18 +5,23 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/smithy-go/logging"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
 	"github.com/pkg/errors"
 )
 
+// ErrObjectNotFound is returned by GetS3Object when the requested key does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
 // Controller represents a wrapper for Controller services providing S3 and SSM functionality with context and logging support.
 type Controller struct {
 	ctx    context.Context
@@ -62,14 +67,14 @@ func NewController(opts ...Option) (*Controller, error) {
 // GetSecret retrieves a secret value from Controller SSM Parameter Store using the provided key.
 // If encrypted is true, the secret is returned decrypted.
 // Returns the secret value as a string pointer or an error if retrieval fails.
-func (a *Controller) GetSecret(key string, encrypted bool) (*string, error) {
+func (a *Controller) GetSecret(ctx context.Context, key string, encrypted bool) (*string, error) {
 	a.logger.With("key", key).Debug("fetching SSM secret...")
-	ssmResponse, err := a.ssmClient.GetParameter(a.ctx, &ssm.GetParameterInput{
+	ssmResponse, err := a.ssmClient.GetParameter(ctx, &ssm.GetParameterInput{
 		Name:           aws.String(key),
 		WithDecryption: aws.Bool(encrypted),
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to load SSM parameters")
+		return nil, classify(errors.Wrap(err, "failed to load SSM parameters"))
 	}
 	return ssmResponse.Parameter.Value, nil
 }
@@ -77,22 +82,71 @@ func (a *Controller) GetSecret(key string, encrypted bool) (*string, error) {
 // PutS3Object uploads a JSON object to the specified S3 bucket with a key formatted as a timestamp and the provided ID.
 // The method takes the event type, bucket name, and the object body as a byte slice as parameters.
 // Returns an error if the S3 upload fails or if the bucket name is empty.
-func (a *Controller) PutS3Object(id string, bucket string, body []byte) error {
+func (a *Controller) PutS3Object(ctx context.Context, id string, bucket string, body []byte) error {
 	if bucket != "" {
 		key := fmt.Sprintf("%s.%s", time.Now().UTC().Format(time.RFC3339Nano), id)
-		_, err := a.s3Client.PutObject(a.ctx, &s3.PutObjectInput{
+		_, err := a.s3Client.PutObject(ctx, &s3.PutObjectInput{
 			Bucket:      &bucket,
 			Key:         aws.String(key),
 			Body:        bytes.NewReader(body),
 			ContentType: aws.String("application/json"),
 		})
 		if err != nil {
-			return errors.Wrap(err, "failed to put object to S3")
+			return classify(errors.Wrap(err, "failed to put object to S3"))
 		}
 	}
 	return nil
 }
 
+// GetS3Object downloads the object stored under the given key of the S3 bucket.
+// Returns ErrObjectNotFound if the key does not exist.
+func (a *Controller) GetS3Object(ctx context.Context, bucket, key string) ([]byte, error) {
+	out, err := a.s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	})
+	if err != nil {
+		var noSuchKey *s3types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, classify(errors.Wrap(err, "failed to get object from S3"))
+	}
+	defer func() { _ = out.Body.Close() }()
+	body, err := io.ReadAll(out.Body)
+	return body, errors.Wrap(err, "failed to read object from S3")
+}
+
+// ListS3Objects lists the keys of the objects of the S3 bucket starting with the given prefix, in lexicographical order.
+func (a *Controller) ListS3Objects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(a.s3Client, &s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, classify(errors.Wrap(err, "failed to list objects in S3"))
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
+
+// PutS3ObjectWithKey uploads a JSON object under the given key of the S3 bucket, replacing any existing object.
+func (a *Controller) PutS3ObjectWithKey(ctx context.Context, bucket, key string, body []byte) error {
+	_, err := a.s3Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &bucket,
+		Key:         &key,
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String("application/json"),
+	})
+	return errors.Wrap(err, "failed to put object to S3")
+}
+
 type awsLogger struct {
 	logger *slog.Logger
 }
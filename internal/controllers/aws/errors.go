@@ -0,0 +1,42 @@
+package aws
+
+import (
+	"net/http"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/smithy-go"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/pkg/errors"
+)
+
+// classify maps an AWS API error to its promotion.ErrorKind: throttling and server faults are transient,
+// access and credential failures are permanent.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *promotion.ClassifiedError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "Throttling", "ThrottlingException", "TooManyRequestsException", "RequestLimitExceeded", "SlowDown":
+			return promotion.NewError(promotion.KindTransient, err)
+		case "AccessDenied", "AccessDeniedException", "UnrecognizedClientException", "InvalidClientTokenId",
+			"ExpiredToken", "ExpiredTokenException", "ParameterNotFound":
+			return promotion.NewError(promotion.KindAuth, err)
+		}
+		if apiErr.ErrorFault() == smithy.FaultServer {
+			return promotion.NewError(promotion.KindTransient, err)
+		}
+	}
+
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) && respErr.HTTPStatusCode() >= http.StatusInternalServerError {
+		return promotion.NewError(promotion.KindTransient, err)
+	}
+	return err
+}
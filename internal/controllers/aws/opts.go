@@ -12,7 +12,8 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
-// WithContext sets a custom context to be used by the Controller instance for request operations.
+// WithContext sets a custom context to be used by the Controller instance while loading its AWS configuration.
+// Request operations use the context passed to each method instead.
 func WithContext(ctx context.Context) Option {
 	return func(a *Controller) {
 		a.ctx = ctx
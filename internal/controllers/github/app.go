@@ -0,0 +1,133 @@
+package github
+
+import (
+	"cmp"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/pkg/errors"
+
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+// appJWTLifetime is the lifetime of the JWTs authenticating as the app, within the 10 minutes allowed by GitHub.
+const appJWTLifetime = 9 * time.Minute
+
+// ListInstallations lists the installations of the app. Listing installations requires authenticating as the app,
+// which is only supported with credentials signing with a private key file.
+func (g *Controller) ListInstallations(ctx context.Context) ([]*github.Installation, error) {
+	token, err := g.appJWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	client, err := github.NewClient(github.WithTransport(g.retryTransport()), github.WithAuthToken(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create github app client")
+	}
+
+	var installations []*github.Installation
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.Apps.ListInstallations(WithOperation(ctx, "list-installations"), opts)
+		if err != nil {
+			return nil, classify(errors.Wrap(err, "failed to list installations"))
+		}
+		installations = append(installations, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return installations, nil
+}
+
+// ListInstallationRepositories lists the repositories the installation of the given clients has access to.
+func (g *Controller) ListInstallationRepositories(ctx context.Context, clients *Client) ([]*github.Repository, error) {
+	var repos []*github.Repository
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := clients.V3.Apps.ListRepos(WithOperation(ctx, "list-installation-repositories"), opts)
+		if err != nil {
+			return nil, classify(errors.Wrapf(err, "failed to list repositories of installation %d", clients.installationID))
+		}
+		repos = append(repos, page.Repositories...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return repos, nil
+}
+
+// appJWT returns a JWT authenticating as the app, signed with the private key of the credentials.
+func (g *Controller) appJWT(now time.Time) (string, error) {
+	if g.AppID == 0 {
+		return "", promotion.NewError(promotion.KindAuth, errors.New("missing app ID: authenticating as the app requires the ssm auth mode"))
+	}
+	key, err := g.appPrivateKey()
+	if err != nil {
+		return "", promotion.NewError(promotion.KindAuth, err)
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		// Backdated to cope with clock drift
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(g.AppID, 10),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", promotion.NewError(promotion.KindAuth, errors.Wrap(err, "failed to sign app JWT"))
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// appPrivateKey parses the private key of the credentials, given either as a PEM block or as the path to a PEM file.
+func (g *Controller) appPrivateKey() (*rsa.PrivateKey, error) {
+	if provider := cmp.Or(g.Provider, "file"); provider != "file" {
+		return nil, errors.Errorf("authenticating as the app is not supported with the %s provider", provider)
+	}
+	material := []byte(strings.TrimSpace(cmp.Or(g.Key, g.PrivateKey)))
+	if len(material) == 0 {
+		return nil, errors.New("no key or private_key provided in credentials")
+	}
+	if !strings.HasPrefix(string(material), "-----BEGIN") {
+		var err error
+		if material, err = os.ReadFile(filepath.Clean(string(material))); err != nil {
+			return nil, errors.Wrap(err, "failed to read private key file")
+		}
+	}
+
+	block, _ := pem.Decode(material)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse private key")
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.Errorf("unsupported private key type %T", parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"container/list"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Cache eviction reasons reported to CacheHooks.OnEvict.
+const (
+	// EvictionExpired is reported when an entry outlived the cache TTL.
+	EvictionExpired = "expired"
+	// EvictionCapacity is reported when the least recently used entry is evicted to honour the maximum number of entries.
+	EvictionCapacity = "capacity"
+	// EvictionInvalidated is reported when an entry was explicitly invalidated.
+	EvictionInvalidated = "invalidated"
+)
+
+// CacheHooks are optional callbacks invoked on cache activity, e.g. to feed metrics. They must not call back into the cache.
+type CacheHooks struct {
+	OnHit   func(installationID int64)
+	OnMiss  func(installationID int64)
+	OnEvict func(installationID int64, reason string)
+}
+
+// CacheStats holds the cumulative counters of a ClientCache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Entries   int
+}
+
+// LogValue returns the counters as a structured log group.
+func (s CacheStats) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("hits", s.Hits),
+		slog.Uint64("misses", s.Misses),
+		slog.Uint64("evictions", s.Evictions),
+		slog.Int("entries", s.Entries))
+}
+
+// CacheOption is a functional option used to configure a ClientCache.
+type CacheOption func(*ClientCache)
+
+// WithCacheTTL sets the duration after which cached clients are discarded. A zero TTL disables expiry.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *ClientCache) {
+		c.ttl = ttl
+	}
+}
+
+// WithCacheMaxEntries sets the maximum number of cached installations. A zero value disables the limit.
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(c *ClientCache) {
+		c.maxEntries = maxEntries
+	}
+}
+
+// WithCacheHooks sets the callbacks invoked on cache hits, misses and evictions.
+func WithCacheHooks(hooks CacheHooks) CacheOption {
+	return func(c *ClientCache) {
+		c.hooks = hooks
+	}
+}
+
+// ClientCache is a concurrency-safe cache of GitHub clients keyed by installation ID, bounded by TTL and LRU eviction.
+type ClientCache struct {
+	ttl        time.Duration
+	maxEntries int
+	hooks      CacheHooks
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[int64]*list.Element
+	lru     *list.List // front is most recently used
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	installationID int64
+	client         *Client
+	expires        time.Time
+}
+
+// NewClientCache creates an empty ClientCache with the given options.
+func NewClientCache(opts ...CacheOption) *ClientCache {
+	_inst := &ClientCache{
+		now:     time.Now,
+		entries: make(map[int64]*list.Element),
+		lru:     list.New(),
+	}
+	for _, opt := range opts {
+		opt(_inst)
+	}
+	return _inst
+}
+
+// Get returns the cached client for the given installation ID, if present and not expired.
+func (c *ClientCache) Get(installationID int64) (*Client, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, found := c.entries[installationID]; found {
+		entry := elem.Value.(*cacheEntry)
+		if c.ttl <= 0 || c.now().Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.stats.Hits++
+			c.call(c.hooks.OnHit, installationID)
+			return entry.client, true
+		}
+		c.remove(elem, EvictionExpired)
+	}
+	c.stats.Misses++
+	c.call(c.hooks.OnMiss, installationID)
+	return nil, false
+}
+
+// Put caches the client of the given installation ID, evicting the least recently used entries if needed.
+func (c *ClientCache) Put(installationID int64, client *Client) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{installationID: installationID, client: client, expires: c.now().Add(c.ttl)}
+	if elem, found := c.entries[installationID]; found {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[installationID] = c.lru.PushFront(entry)
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back(), EvictionCapacity)
+	}
+}
+
+// Invalidate discards the cached client of the given installation ID, e.g. once the app is uninstalled.
+func (c *ClientCache) Invalidate(installationID int64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.entries[installationID]
+	if found {
+		c.remove(elem, EvictionInvalidated)
+	}
+	return found
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *ClientCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// remove evicts the given element. The caller must hold the lock.
+func (c *ClientCache) remove(elem *list.Element, reason string) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.installationID)
+	c.stats.Evictions++
+	if c.hooks.OnEvict != nil {
+		c.hooks.OnEvict(entry.installationID, reason)
+	}
+}
+
+func (c *ClientCache) call(hook func(int64), installationID int64) {
+	if hook != nil {
+		hook(installationID)
+	}
+}
@@ -0,0 +1,90 @@
+package github_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientCache(t *testing.T) {
+	t.Run("lru", func(t *testing.T) {
+		var evicted []int64
+		cache := github.NewClientCache(
+			github.WithCacheMaxEntries(2),
+			github.WithCacheHooks(github.CacheHooks{
+				OnEvict: func(installationID int64, reason string) {
+					assert.Equal(t, github.EvictionCapacity, reason)
+					evicted = append(evicted, installationID)
+				},
+			}))
+
+		cache.Put(1, &github.Client{})
+		cache.Put(2, &github.Client{})
+		_, found := cache.Get(1) // 2 becomes the least recently used
+		assert.True(t, found)
+		cache.Put(3, &github.Client{})
+
+		assert.Equal(t, []int64{2}, evicted)
+		_, found = cache.Get(2)
+		assert.False(t, found)
+		assert.Equal(t, github.CacheStats{Hits: 1, Misses: 1, Evictions: 1, Entries: 2}, cache.Stats())
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		cache := github.NewClientCache(github.WithCacheTTL(10 * time.Millisecond))
+		cache.Put(1, &github.Client{})
+		_, found := cache.Get(1)
+		assert.True(t, found)
+
+		time.Sleep(20 * time.Millisecond)
+		_, found = cache.Get(1)
+		assert.False(t, found)
+		assert.Equal(t, github.CacheStats{Hits: 1, Misses: 1, Evictions: 1}, cache.Stats())
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		cache := github.NewClientCache()
+		cache.Put(1, &github.Client{})
+		assert.True(t, cache.Invalidate(1))
+		assert.False(t, cache.Invalidate(1))
+		_, found := cache.Get(1)
+		assert.False(t, found)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		var hits, misses atomic.Uint64
+		cache := github.NewClientCache(
+			github.WithCacheMaxEntries(8),
+			github.WithCacheTTL(time.Millisecond),
+			github.WithCacheHooks(github.CacheHooks{
+				OnHit:  func(int64) { hits.Add(1) },
+				OnMiss: func(int64) { misses.Add(1) },
+			}))
+
+		var wg sync.WaitGroup
+		for worker := range 16 {
+			wg.Go(func() {
+				for i := range 500 {
+					id := int64((worker + i) % 32)
+					if _, found := cache.Get(id); !found {
+						cache.Put(id, &github.Client{})
+					}
+					if i%50 == 0 {
+						cache.Invalidate(id)
+					}
+				}
+			})
+		}
+		wg.Wait()
+
+		stats := cache.Stats()
+		assert.Equal(t, uint64(16*500), stats.Hits+stats.Misses)
+		assert.Equal(t, stats.Hits, hits.Load())
+		assert.Equal(t, stats.Misses, misses.Load())
+		assert.LessOrEqual(t, stats.Entries, 8)
+	})
+}
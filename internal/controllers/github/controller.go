@@ -25,6 +25,7 @@ import (
 	"github.com/isometry/gh-promotion-app/internal/controllers/aws"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/templates"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
 	"github.com/isometry/gh-promotion-app/internal/validation"
 
@@ -57,6 +58,11 @@ func NewController(opts ...GHOption) (*Controller, error) {
 	if _inst.logger == nil {
 		_inst.logger = helpers.NewNoopLogger()
 	}
+	if _inst.clientCache == nil {
+		_inst.clientCache = NewClientCache(
+			WithCacheTTL(config.GitHub.ClientCache.TTL),
+			WithCacheMaxEntries(config.GitHub.ClientCache.MaxEntries))
+	}
 	_inst.logger.With("authMode", _inst.authMode)
 	return _inst, nil
 }
@@ -68,9 +74,6 @@ type Client struct {
 	V4             *githubv4.Client
 }
 
-// _clientCache - cache of Controller clients.
-var _clientCache = make(map[int64]*Client)
-
 // Controller encapsulates Controller-related operations and credentials management for various authentication modes.
 type Controller struct {
 	Credentials
@@ -80,6 +83,7 @@ type Controller struct {
 	ctx           context.Context
 	logger        *slog.Logger
 	awsController *aws.Controller
+	clientCache   *ClientCache
 	ghaitInstance ghait.GHAIT // initialized by RetrieveCredentials in ssm mode
 }
 
@@ -95,11 +99,11 @@ type Credentials struct {
 }
 
 // RetrieveCredentials fetches the Controller credentials from the environment or SSM.
-func (g *Controller) RetrieveCredentials() error {
+func (g *Controller) RetrieveCredentials(ctx context.Context) error {
 	switch strings.TrimSpace(strings.ToLower(g.authMode)) {
 	case "token":
 		if g.Token == "" {
-			return errors.New("missing [GITHUB_TOKEN]]")
+			return promotion.NewError(promotion.KindAuth, errors.New("missing [GITHUB_TOKEN]]"))
 		}
 		return nil
 	case "ssm":
@@ -108,21 +112,22 @@ func (g *Controller) RetrieveCredentials() error {
 			return nil
 		}
 		g.logger.Debug("retrieving credentials from SSM...")
-		secret, err := g.awsController.GetSecret(g.ssmKey, true)
+		secret, err := g.awsController.GetSecret(ctx, g.ssmKey, true)
 		if err != nil {
 			return errors.Wrap(err, "failed to fetch credentials from SSM")
 		}
 		if err = json.Unmarshal([]byte(*secret), &g.Credentials); err != nil {
-			return errors.Wrap(err, "failed to unmarshal credentials")
+			return promotion.NewError(promotion.KindAuth, errors.Wrap(err, "failed to unmarshal credentials"))
 		}
 		key := cmp.Or(g.Key, g.PrivateKey)
 		if key == "" {
-			return errors.New("no key or private_key provided in credentials")
+			return promotion.NewError(promotion.KindAuth, errors.New("no key or private_key provided in credentials"))
 		}
 		cfg := ghait.NewConfig(g.AppID, 0, cmp.Or(g.Provider, "file"), key)
-		ga, err := ghait.NewGHAIT(g.ctx, cfg)
+		// The ghait instance outlives the request, so it is bound to the controller context
+		ga, err := ghait.NewGHAIT(g.ctx, cfg) //nolint:contextcheck // Long-lived instance
 		if err != nil {
-			return errors.Wrapf(err, "failed to initialize ghait (%s)", cmp.Or(g.Provider, "file"))
+			return promotion.NewError(promotion.KindAuth, errors.Wrapf(err, "failed to initialize ghait (%s)", cmp.Or(g.Provider, "file")))
 		}
 		g.ghaitInstance = ga
 	case "vault":
@@ -134,31 +139,35 @@ func (g *Controller) RetrieveCredentials() error {
 }
 
 // GetGitHubClients returns a Controller client for the given installation ID or token.
-func (g *Controller) GetGitHubClients(body []byte) (*Client, error) {
+func (g *Controller) GetGitHubClients(ctx context.Context, body []byte) (*Client, error) {
 	var eventInstallationID EventInstallationID
 	if err := json.Unmarshal(body, &eventInstallationID); err != nil {
-		return nil, fmt.Errorf("no installation ID found. error: %w", err)
+		return nil, promotion.NewError(promotion.KindInvalidInput, fmt.Errorf("no installation ID found. error: %w", err))
 	}
 
-	// Cache hit
-	installationID := eventInstallationID.Installation.ID
-	if installationID == nil {
-		return nil, errors.New("no installation ID found")
+	if eventInstallationID.Installation.ID == nil {
+		return nil, promotion.NewError(promotion.KindInvalidInput, errors.New("no installation ID found"))
 	}
-	if client, ok := _clientCache[*installationID]; ok {
-		g.logger.Debug("cache hit. using cached client...", slog.Int64("installationId", *installationID))
+	return g.GetInstallationClients(ctx, *eventInstallationID.Installation.ID)
+}
+
+// GetInstallationClients returns the cached Controller client for the given installation ID, spawning it on a cache miss.
+func (g *Controller) GetInstallationClients(ctx context.Context, installationID int64) (*Client, error) {
+	// Cache hit
+	if client, ok := g.clientCache.Get(installationID); ok {
+		g.logger.Debug("cache hit. using cached client...", slog.Int64("installationId", installationID), slog.Any("cache", g.clientCache.Stats()))
 		return client, nil
 	}
 
 	// Cache miss
-	g.logger.Debug("cache miss. spawning clients...", slog.Int64("installationId", *installationID))
+	g.logger.Debug("cache miss. spawning clients...", slog.Int64("installationId", installationID), slog.Any("cache", g.clientCache.Stats()))
 	var transport http.RoundTripper
 	switch strings.TrimSpace(strings.ToLower(g.authMode)) {
 	case "token":
 		g.logger.Debug("[GITHUB_TOKEN] detected. Spawning clients using PAT...")
 		transport = &oauth2.Transport{
 			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: g.Token}),
-			Base:   &loggingRoundTripper{logger: g.logger},
+			Base:   g.retryTransport(),
 		}
 	case "ssm":
 		g.logger.Debug("creating clients via ghait...", slog.String("provider", cmp.Or(g.Provider, "file")))
@@ -168,16 +177,16 @@ func (g *Controller) GetGitHubClients(body []byte) (*Client, error) {
 		src := &ghaitTokenSource{
 			ctx:            g.ctx,
 			ghait:          g.ghaitInstance,
-			installationID: *installationID,
+			installationID: installationID,
 			logger:         g.logger,
 		}
-		initialToken, err := src.Token()
+		initialToken, err := src.token(ctx)
 		if err != nil {
-			return nil, errors.Wrap(err, "ghait installation token")
+			return nil, classify(errors.Wrap(err, "ghait installation token"))
 		}
 		transport = &oauth2.Transport{
 			Source: oauth2.ReuseTokenSourceWithExpiry(initialToken, src, 5*time.Minute),
-			Base:   &loggingRoundTripper{logger: g.logger},
+			Base:   g.retryTransport(),
 		}
 	default:
 		return nil, errors.New("no valid credentials found")
@@ -189,13 +198,40 @@ func (g *Controller) GetGitHubClients(body []byte) (*Client, error) {
 	}
 	clientV4 := githubv4.NewClient(rateLimiter)
 	// Persist cache entry
-	_clientCache[*installationID] = &Client{
-		installationID: *installationID,
+	client := &Client{
+		installationID: installationID,
 		V3:             clientV3,
 		V4:             clientV4,
 	}
-	g.logger.Debug("successfully cached spawned clients...", slog.Int64("installationID", *installationID))
-	return _clientCache[*installationID], nil
+	g.clientCache.Put(installationID, client)
+	g.logger.Debug("successfully cached spawned clients...", slog.Int64("installationID", installationID))
+	return client, nil
+}
+
+// retryTransport returns the transport retrying transient GitHub API failures according to the configured policies.
+func (g *Controller) retryTransport() http.RoundTripper {
+	return NewRetryTransport(&loggingRoundTripper{logger: g.logger}, RetryPolicies{
+		Default:    config.GitHub.Retry.Default,
+		Operations: config.GitHub.Retry.Operations,
+	}, g.logger)
+}
+
+// InvalidateClients discards the cached clients of the given installation ID.
+func (g *Controller) InvalidateClients(installationID int64) {
+	if g.clientCache.Invalidate(installationID) {
+		g.logger.Info("invalidated cached clients", slog.Int64("installationId", installationID), slog.Any("cache", g.clientCache.Stats()))
+	}
+}
+
+// planned records the action in the dry-run plan carried by the context, if any, and reports whether the mutation must be skipped.
+func (g *Controller) planned(ctx context.Context, action models.PlannedAction) bool {
+	plan := promotion.PlanFromContext(ctx)
+	if plan == nil {
+		return false
+	}
+	g.logger.Info("dry-run: skipping GitHub mutation", slog.Any("action", action))
+	plan.Record(action)
+	return true
 }
 
 // ValidateWebhookSecret verifies the webhook secret against the signature in the provided headers for security validation.
@@ -204,27 +240,45 @@ func (g *Controller) ValidateWebhookSecret(secret []byte, headers map[string]str
 }
 
 // PromotionTargetRefExists checks if a ref exists in the repository.
-func (g *Controller) PromotionTargetRefExists(ctx *promotion.Context) bool {
-	_, _, err := ctx.ClientV3.Git.GetRef(g.ctx, *ctx.Owner, *ctx.Repository, helpers.NormaliseFullRef(ctx.BaseRef))
+func (g *Controller) PromotionTargetRefExists(ctx context.Context, pCtx *promotion.Context) bool {
+	_, _, err := pCtx.ClientV3.Git.GetRef(WithOperation(ctx, "get-ref"), *pCtx.Owner, *pCtx.Repository, helpers.NormaliseFullRef(pCtx.BaseRef))
 	return err == nil
 }
 
+// GetPromotionTargetRefSHA returns the SHA the promotion target ref currently points to.
+func (g *Controller) GetPromotionTargetRefSHA(ctx context.Context, pCtx *promotion.Context) (string, error) {
+	ref, _, err := pCtx.ClientV3.Git.GetRef(WithOperation(ctx, "get-ref"), *pCtx.Owner, *pCtx.Repository, helpers.NormaliseFullRef(pCtx.BaseRef))
+	if err != nil {
+		return "", classify(errors.Wrap(err, "failed to get target ref"))
+	}
+	return ref.GetObject().GetSHA(), nil
+}
+
 // CreatePromotionTargetRef creates a new ref in the repository.
-func (g *Controller) CreatePromotionTargetRef(pCtx *promotion.Context) (*github.Reference, error) {
+func (g *Controller) CreatePromotionTargetRef(ctx context.Context, pCtx *promotion.Context) (*github.Reference, error) {
 	// Fetch the first commit on the head ref
-	rootCommit, err := g.GetPromotionSourceRootRef(pCtx)
+	rootCommit, err := g.GetPromotionSourceRootRef(ctx, pCtx)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to fetch root commit")
 	}
-	ref, _, err := pCtx.ClientV3.Git.CreateRef(g.ctx, *pCtx.Owner, *pCtx.Repository, github.CreateRef{
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-ref",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Ref:        helpers.NormaliseFullRef(pCtx.BaseRef),
+		SHA:        *rootCommit,
+	}) {
+		return &github.Reference{Ref: new(helpers.NormaliseFullRef(pCtx.BaseRef)), Object: &github.GitObject{SHA: rootCommit}}, nil
+	}
+	ref, _, err := pCtx.ClientV3.Git.CreateRef(WithOperation(ctx, "create-ref"), *pCtx.Owner, *pCtx.Repository, github.CreateRef{
 		Ref: helpers.NormaliseFullRef(pCtx.BaseRef),
 		SHA: *rootCommit,
 	})
-	return ref, errors.Wrap(err, "failed to create ref")
+	return ref, classify(errors.Wrap(err, "failed to create ref"))
 }
 
 // GetPromotionSourceRootRef fetches the root commit present on the head ref.
-func (g *Controller) GetPromotionSourceRootRef(pCtx *promotion.Context) (*string, error) {
+func (g *Controller) GetPromotionSourceRootRef(ctx context.Context, pCtx *promotion.Context) (*string, error) {
 	var allCommits []*github.RepositoryCommit
 	opts := &github.CommitsListOptions{
 		SHA: *pCtx.HeadRef,
@@ -234,9 +288,9 @@ func (g *Controller) GetPromotionSourceRootRef(pCtx *promotion.Context) (*string
 	}
 
 	for {
-		commits, resp, err := pCtx.ClientV3.Repositories.ListCommits(context.Background(), *pCtx.Owner, *pCtx.Repository, opts)
+		commits, resp, err := pCtx.ClientV3.Repositories.ListCommits(WithOperation(ctx, "list-commits"), *pCtx.Owner, *pCtx.Repository, opts)
 		if err != nil {
-			return nil, err
+			return nil, classify(err)
 		}
 		allCommits = append(allCommits, commits...)
 		if resp.NextPage == 0 {
@@ -253,14 +307,26 @@ func (g *Controller) GetPromotionSourceRootRef(pCtx *promotion.Context) (*string
 }
 
 // FindPullRequest searches for an open pull request that matches the promotion request.
-func (g *Controller) FindPullRequest(pCtx *promotion.Context) (*github.PullRequest, error) {
+func (g *Controller) FindPullRequest(ctx context.Context, pCtx *promotion.Context) (*github.PullRequest, error) {
+	prs, err := g.FindPullRequests(ctx, pCtx)
+	if err != nil {
+		return nil, err
+	}
+	pCtx.HeadRef = prs[0].Head.Ref
+	pCtx.BaseRef = prs[0].Base.Ref
+	return prs[0], nil
+}
+
+// FindPullRequests searches for the open pull requests that match the promotion request, e.g. the promotion requests
+// of a head SHA fanning out of a stage into several stages.
+func (g *Controller) FindPullRequests(ctx context.Context, pCtx *promotion.Context) ([]*github.PullRequest, error) {
 	g.logger.Info("finding promotion requests...", slog.String("owner", *pCtx.Owner), slog.String("repository", *pCtx.Repository))
 	prListOptions := &github.PullRequestListOptions{
 		State: "open",
 	}
 
 	if pCtx.HeadSHA == nil {
-		return nil, errors.New("head SHA is missing")
+		return nil, promotion.NewError(promotion.KindInvalidInput, errors.New("head SHA is missing"))
 	}
 
 	if pCtx.HeadRef != nil && *pCtx.HeadRef != "" {
@@ -274,34 +340,248 @@ func (g *Controller) FindPullRequest(pCtx *promotion.Context) (*github.PullReque
 		prListOptions.Base = *pCtx.BaseRef
 	}
 
-	prs, _, err := pCtx.ClientV3.PullRequests.List(g.ctx, *pCtx.Owner, *pCtx.Repository, prListOptions)
+	prs, _, err := pCtx.ClientV3.PullRequests.List(WithOperation(ctx, "list-pull-requests"), *pCtx.Owner, *pCtx.Repository, prListOptions)
 	if err != nil {
 		g.logger.Error("failed to list pull requests...", slog.Any("error", err))
-		return nil, err
+		return nil, classify(err)
 	}
 
-	g.logger.Debug("Attempting to find matching promotion request...")
+	g.logger.Debug("Attempting to find matching promotion requests...")
+	var matching []*github.PullRequest
 	for _, pr := range prs {
 		if *pr.Head.SHA == *pCtx.HeadSHA && pCtx.Promoter.IsPromotionRequest(pr) {
 			g.logger.Info("found matching promotion request...", slog.String("pr", *pr.URL))
-			pCtx.HeadRef = pr.Head.Ref
-			pCtx.BaseRef = pr.Base.Ref
-			return pr, nil
+			matching = append(matching, pr)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, promotion.NewError(promotion.KindInvalidInput, ErrNoPromotionRequest)
+	}
+	return matching, nil
+}
+
+// StageContainsSHA reports whether the given stage branch contains the commit, i.e. the commit is its head or one of its ancestors.
+func (g *Controller) StageContainsSHA(ctx context.Context, pCtx *promotion.Context, stage, sha string) (bool, error) {
+	comparison, _, err := pCtx.ClientV3.Repositories.CompareCommits(WithOperation(ctx, "compare-commits"), *pCtx.Owner, *pCtx.Repository,
+		helpers.NormaliseRef(stage), sha, &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return false, classify(errors.Wrapf(err, "failed to compare %s with %s", stage, sha))
+	}
+	// The commit is behind the stage, or identical to its head, if the stage contains it
+	return comparison.GetStatus() == "behind" || comparison.GetStatus() == "identical", nil
+}
+
+// HasSuccessfulDeployment reports whether the latest deployment of the head SHA to the given environment succeeded.
+func (g *Controller) HasSuccessfulDeployment(ctx context.Context, pCtx *promotion.Context, environment string) (bool, error) {
+	deployments, _, err := pCtx.ClientV3.Repositories.ListDeployments(WithOperation(ctx, "list-deployments"), *pCtx.Owner, *pCtx.Repository, &github.DeploymentsListOptions{
+		SHA:         *pCtx.HeadSHA,
+		Environment: environment,
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return false, classify(errors.Wrap(err, "failed to list deployments"))
+	}
+	if len(deployments) == 0 {
+		return false, nil
+	}
+	statuses, _, err := pCtx.ClientV3.Repositories.ListDeploymentStatuses(WithOperation(ctx, "list-deployment-statuses"), *pCtx.Owner, *pCtx.Repository, deployments[0].GetID(), &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return false, classify(errors.Wrap(err, "failed to list deployment statuses"))
+	}
+	return len(statuses) > 0 && statuses[0].GetState() == "success", nil
+}
+
+// GetRequiredChecks returns the status checks required by the branch protection and the rulesets of the promotion target ref.
+func (g *Controller) GetRequiredChecks(ctx context.Context, pCtx *promotion.Context) ([]string, error) {
+	var required []string
+	branch := helpers.NormaliseRef(pCtx.BaseRef)
+
+	checks, resp, err := pCtx.ClientV3.Repositories.GetRequiredStatusChecks(WithOperation(ctx, "get-required-status-checks"), *pCtx.Owner, *pCtx.Repository, branch)
+	switch {
+	case resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden):
+		// The branch is not protected, or the app may not read its protection
+		g.logger.Debug("no readable branch protection", slog.String("branch", branch), slog.Int("status", resp.StatusCode))
+	case err != nil:
+		return nil, classify(errors.Wrap(err, "failed to get required status checks"))
+	default:
+		required = append(required, checks.GetContexts()...)
+		for _, check := range checks.GetChecks() {
+			required = append(required, check.Context)
+		}
+	}
+
+	rules, resp, err := pCtx.ClientV3.Repositories.ListRulesForBranch(WithOperation(ctx, "list-branch-rules"), *pCtx.Owner, *pCtx.Repository, branch, &github.ListOptions{PerPage: 100})
+	switch {
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		g.logger.Debug("no branch rules", slog.String("branch", branch))
+	case err != nil:
+		return nil, classify(errors.Wrap(err, "failed to list branch rules"))
+	default:
+		for _, rule := range rules.RequiredStatusChecks {
+			for _, check := range rule.Parameters.RequiredStatusChecks {
+				required = append(required, check.Context)
+			}
+		}
+	}
+
+	slices.Sort(required)
+	return slices.Compact(required), nil
+}
+
+// ListCommitChecks returns the commit statuses and the check runs reported on the head SHA.
+func (g *Controller) ListCommitChecks(ctx context.Context, pCtx *promotion.Context) ([]*github.RepoStatus, []*github.CheckRun, error) {
+	var statuses []*github.RepoStatus
+	statusOpts := &github.ListOptions{PerPage: 100}
+	for {
+		combined, resp, err := pCtx.ClientV3.Repositories.GetCombinedStatus(WithOperation(ctx, "get-combined-status"), *pCtx.Owner, *pCtx.Repository, *pCtx.HeadSHA, statusOpts)
+		if err != nil {
+			return nil, nil, classify(errors.Wrap(err, "failed to get combined status"))
+		}
+		statuses = append(statuses, combined.Statuses...)
+		if resp.NextPage == 0 {
+			break
+		}
+		statusOpts.Page = resp.NextPage
+	}
+
+	var checkRuns []*github.CheckRun
+	checkRunOpts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		results, resp, err := pCtx.ClientV3.Checks.ListCheckRunsForRef(WithOperation(ctx, "list-check-runs"), *pCtx.Owner, *pCtx.Repository, *pCtx.HeadSHA, checkRunOpts)
+		if err != nil {
+			return nil, nil, classify(errors.Wrap(err, "failed to list check runs"))
+		}
+		checkRuns = append(checkRuns, results.CheckRuns...)
+		if resp.NextPage == 0 {
+			break
+		}
+		checkRunOpts.Page = resp.NextPage
+	}
+	return statuses, checkRuns, nil
+}
+
+// ListPullRequestReviews lists the reviews of the promotion request, in chronological order.
+func (g *Controller) ListPullRequestReviews(ctx context.Context, pCtx *promotion.Context) ([]*github.PullRequestReview, error) {
+	var reviews []*github.PullRequestReview
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := pCtx.ClientV3.PullRequests.ListReviews(WithOperation(ctx, "list-reviews"), *pCtx.Owner, *pCtx.Repository, pCtx.PullRequest.GetNumber(), opts)
+		if err != nil {
+			return nil, classify(errors.Wrap(err, "failed to list pull request reviews"))
+		}
+		reviews = append(reviews, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return reviews, nil
+}
+
+// ListTeamMembers lists the logins of the members of the team, including the members of its child teams.
+// The team is either a team slug of the repository owner or an "org/team-slug" reference.
+func (g *Controller) ListTeamMembers(ctx context.Context, pCtx *promotion.Context, team string) ([]string, error) {
+	org, slug, found := strings.Cut(team, "/")
+	if !found {
+		org, slug = *pCtx.Owner, team
+	}
+	var members []string
+	opts := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		page, resp, err := pCtx.ClientV3.Teams.ListTeamMembersBySlug(WithOperation(ctx, "list-team-members"), org, slug, opts)
+		if err != nil {
+			return nil, classify(errors.Wrapf(err, "failed to list members of team %s", team))
+		}
+		for _, member := range page {
+			members = append(members, member.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return members, nil
+}
+
+// GetRepository fetches the repository of the promotion context, including its custom properties.
+func (g *Controller) GetRepository(ctx context.Context, pCtx *promotion.Context) (*github.Repository, error) {
+	repo, _, err := pCtx.ClientV3.Repositories.Get(WithOperation(ctx, "get-repository"), *pCtx.Owner, *pCtx.Repository)
+	if err != nil {
+		return nil, classify(errors.Wrap(err, "failed to get repository"))
+	}
+	return repo, nil
+}
+
+// ListPromotionRequests lists the open pull requests of the repository that are promotion requests of the given promoter.
+func (g *Controller) ListPromotionRequests(ctx context.Context, pCtx *promotion.Context, promoter *promotion.Promoter) ([]*github.PullRequest, error) {
+	var promotionRequests []*github.PullRequest
+	opts := &github.PullRequestListOptions{
+		State: "open",
+		ListOptions: github.ListOptions{
+			PerPage: 100, // max allowed value
+		},
+	}
+
+	for {
+		prs, resp, err := pCtx.ClientV3.PullRequests.List(WithOperation(ctx, "list-pull-requests"), *pCtx.Owner, *pCtx.Repository, opts)
+		if err != nil {
+			return nil, classify(errors.Wrap(err, "failed to list pull requests"))
+		}
+		for _, pr := range prs {
+			if promoter.IsPromotionRequest(pr) {
+				promotionRequests = append(promotionRequests, pr)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
 		}
+		opts.Page = resp.NextPage
+	}
+	return promotionRequests, nil
+}
+
+// ClosePullRequest closes the given pull request without merging it.
+func (g *Controller) ClosePullRequest(ctx context.Context, pCtx *promotion.Context, number int) error {
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "close-pull-request",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Details:    map[string]any{"number": number},
+	}) {
+		return nil
 	}
+	_, _, err := pCtx.ClientV3.PullRequests.Edit(WithOperation(ctx, "update-pull-request"), *pCtx.Owner, *pCtx.Repository, number, &github.PullRequest{State: new("closed")})
+	return classify(errors.Wrapf(err, "failed to close pull request #%d", number))
+}
 
-	return nil, errors.New("no matching promotion request found")
+// GetPromotionSourceRefSHA returns the SHA the promotion source ref currently points to.
+func (g *Controller) GetPromotionSourceRefSHA(ctx context.Context, pCtx *promotion.Context) (string, error) {
+	ref, _, err := pCtx.ClientV3.Git.GetRef(WithOperation(ctx, "get-ref"), *pCtx.Owner, *pCtx.Repository, helpers.NormaliseFullRef(pCtx.HeadRef))
+	if err != nil {
+		return "", classify(errors.Wrap(err, "failed to get source ref"))
+	}
+	return ref.GetObject().GetSHA(), nil
+}
+
+// PromotionSourceAheadBy returns the number of commits of the promotion source ref missing from the promotion target ref.
+func (g *Controller) PromotionSourceAheadBy(ctx context.Context, pCtx *promotion.Context) (int, error) {
+	comparison, _, err := pCtx.ClientV3.Repositories.CompareCommits(WithOperation(ctx, "compare-commits"), *pCtx.Owner, *pCtx.Repository,
+		helpers.NormaliseRef(pCtx.BaseRef), helpers.NormaliseRef(pCtx.HeadRef), &github.ListOptions{PerPage: 1})
+	if err != nil {
+		return 0, classify(errors.Wrap(err, "failed to compare promotion refs"))
+	}
+	return comparison.GetAheadBy(), nil
 }
 
 // ListPullRequestCommits fetches all commits present in the pull request.
-func (g *Controller) ListPullRequestCommits(pCtx *promotion.Context) ([]*github.RepositoryCommit, error) {
+func (g *Controller) ListPullRequestCommits(ctx context.Context, pCtx *promotion.Context) ([]*github.RepositoryCommit, error) {
 	var allCommits []*github.RepositoryCommit
 	options := &github.ListOptions{PerPage: 60}
 
 	for {
-		commits, resp, err := pCtx.ClientV3.PullRequests.ListCommits(g.ctx, *pCtx.Owner, *pCtx.Repository, *pCtx.PullRequest.Number, options)
+		commits, resp, err := pCtx.ClientV3.PullRequests.ListCommits(WithOperation(ctx, "list-pull-request-commits"), *pCtx.Owner, *pCtx.Repository, *pCtx.PullRequest.Number, options)
 		if err != nil {
-			return nil, err
+			return nil, classify(err)
 		}
 		allCommits = append(allCommits, commits...)
 		if resp.NextPage == 0 {
@@ -319,30 +599,59 @@ func (g *Controller) ListPullRequestCommits(pCtx *promotion.Context) ([]*github.
 }
 
 // CreatePullRequest creates a new pull request in the repository.
-func (g *Controller) CreatePullRequest(ctx *promotion.Bus) (*github.PullRequest, error) {
-	pCtx := ctx.Context
+func (g *Controller) CreatePullRequest(ctx context.Context, bus *promotion.Bus) (*github.PullRequest, error) {
+	pCtx := bus.Context
 
-	draftMode := helpers.GetCustomProperty[bool](ctx.Repository.CustomProperties, config.Promotion.Push.CreatePullRequestInDraftModeKey)
-	pr, _, err := pCtx.ClientV3.PullRequests.Create(g.ctx, *pCtx.Owner, *pCtx.Repository, &github.NewPullRequest{
+	draftMode := helpers.GetCustomProperty[bool](bus.Repository.CustomProperties, config.Promotion.Push.CreatePullRequestInDraftModeKey)
+	newPR := &github.NewPullRequest{
 		Title:               g.RequestTitle(*pCtx),
 		Head:                pCtx.HeadRef,
 		Base:                pCtx.BaseRef,
 		MaintainerCanModify: new(false),
 		Draft:               new(draftMode),
-	})
+	}
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-pull-request",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Ref:        *pCtx.BaseRef,
+		SHA:        helpers.String(pCtx.HeadSHA),
+		Details:    map[string]any{"title": *newPR.Title, "head": *pCtx.HeadRef, "draft": draftMode},
+	}) {
+		// Stand-in for the pull request that would have been created, so that later processors can carry on
+		return &github.PullRequest{
+			Title: newPR.Title,
+			URL:   new("dry-run"),
+			Draft: newPR.Draft,
+			// New pull requests are unlabelled: nil labels would have them listed for a pull request that does not exist
+			Labels: []*github.Label{},
+			Head:   &github.PullRequestBranch{Ref: pCtx.HeadRef, SHA: pCtx.HeadSHA},
+			Base:   &github.PullRequestBranch{Ref: pCtx.BaseRef},
+		}, nil
+	}
+	pr, _, err := pCtx.ClientV3.PullRequests.Create(WithOperation(ctx, "create-pull-request"), *pCtx.Owner, *pCtx.Repository, newPR)
 
 	if err != nil {
-		return nil, err
+		return nil, classify(err)
 	}
 
 	return pr, nil
 }
 
 // FastForwardRefToSha pushes a commit to a ref, used to merge an open pull request via fast-forward.
-func (g *Controller) FastForwardRefToSha(pCtx *promotion.Context) error {
+func (g *Controller) FastForwardRefToSha(ctx context.Context, pCtx *promotion.Context) error {
 	ctxLogger := g.logger.With(slog.String("headRef", *pCtx.HeadRef), slog.String("headSHA", *pCtx.HeadSHA), slog.String("owner", *pCtx.Owner), slog.String("repository", *pCtx.Repository))
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "fast-forward",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Ref:        helpers.NormaliseFullRef(*pCtx.BaseRef),
+		SHA:        *pCtx.HeadSHA,
+	}) {
+		return nil
+	}
 	ctxLogger.Debug("attempting fast forward...")
-	_, _, err := pCtx.ClientV3.Git.UpdateRef(g.ctx, *pCtx.Owner, *pCtx.Repository,
+	_, _, err := pCtx.ClientV3.Git.UpdateRef(WithOperation(ctx, "update-ref"), *pCtx.Owner, *pCtx.Repository,
 		helpers.NormaliseFullRef(*pCtx.BaseRef),
 		github.UpdateRef{
 			SHA:   *pCtx.HeadSHA,
@@ -350,13 +659,155 @@ func (g *Controller) FastForwardRefToSha(pCtx *promotion.Context) error {
 		})
 	if err != nil {
 		ctxLogger.Error("failed fast forward", slog.Any("error", err))
-		return err
+		return classify(err)
 	}
 
 	ctxLogger.Debug("successful fast forward")
 	return nil
 }
 
+// GetPullRequest fetches the pull request with the given number.
+func (g *Controller) GetPullRequest(ctx context.Context, pCtx *promotion.Context, number int) (*github.PullRequest, error) {
+	pr, _, err := pCtx.ClientV3.PullRequests.Get(WithOperation(ctx, "get-pull-request"), *pCtx.Owner, *pCtx.Repository, number)
+	if err != nil {
+		return nil, classify(errors.Wrapf(err, "failed to get pull request #%d", number))
+	}
+	return pr, nil
+}
+
+// EnqueuePullRequest adds the promotion request to the merge queue of its base ref, expecting its head to be the head SHA.
+func (g *Controller) EnqueuePullRequest(ctx context.Context, pCtx *promotion.Context) error {
+	if pCtx.PullRequest == nil {
+		return promotion.NewError(promotion.KindInvalidInput, errors.New("promotion request is missing"))
+	}
+	ctxLogger := g.logger.With(slog.Int("number", pCtx.PullRequest.GetNumber()), slog.String("headSHA", *pCtx.HeadSHA), slog.String("owner", *pCtx.Owner), slog.String("repository", *pCtx.Repository))
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "enqueue-pull-request",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Ref:        helpers.NormaliseFullRef(*pCtx.BaseRef),
+		SHA:        *pCtx.HeadSHA,
+		Details:    map[string]any{"number": pCtx.PullRequest.GetNumber()},
+	}) {
+		return nil
+	}
+
+	// Pull requests embedded in check suite and check run payloads lack their node ID
+	pr := pCtx.PullRequest
+	if pr.GetNodeID() == "" {
+		var err error
+		if pr, err = g.GetPullRequest(ctx, pCtx, pCtx.PullRequest.GetNumber()); err != nil {
+			return err
+		}
+	}
+
+	var mutation struct {
+		EnqueuePullRequest struct {
+			MergeQueueEntry struct {
+				Position githubv4.Int
+				State    githubv4.String
+			}
+		} `graphql:"enqueuePullRequest(input: $input)"`
+	}
+	ctxLogger.Debug("enqueuing promotion request...")
+	if err := pCtx.ClientV4.Mutate(WithOperation(ctx, "enqueue-pull-request"), &mutation, githubv4.EnqueuePullRequestInput{
+		PullRequestID:   githubv4.ID(pr.GetNodeID()),
+		ExpectedHeadOid: new(githubv4.GitObjectID(*pCtx.HeadSHA)),
+	}, nil); err != nil {
+		ctxLogger.Error("failed to enqueue promotion request", slog.Any("error", err))
+		return classify(errors.Wrap(err, "failed to enqueue pull request"))
+	}
+	entry := mutation.EnqueuePullRequest.MergeQueueEntry
+	ctxLogger.Info("enqueued promotion request", slog.Int("position", int(entry.Position)), slog.String("state", string(entry.State)))
+	return nil
+}
+
+// GetPermissionLevel returns the repository role of the given user, e.g. "write".
+func (g *Controller) GetPermissionLevel(ctx context.Context, pCtx *promotion.Context, login string) (string, error) {
+	level, _, err := pCtx.ClientV3.Repositories.GetPermissionLevel(WithOperation(ctx, "get-permission-level"), *pCtx.Owner, *pCtx.Repository, login)
+	if err != nil {
+		return "", classify(errors.Wrapf(err, "failed to get permission level of %s", login))
+	}
+	// Custom roles are reported with the base permission they inherit from
+	if role := level.GetRoleName(); promotion.IsRole(role) {
+		return role, nil
+	}
+	return level.GetPermission(), nil
+}
+
+// ReactToComment adds a reaction, e.g. "+1", to the given issue comment.
+func (g *Controller) ReactToComment(ctx context.Context, pCtx *promotion.Context, commentID int64, reaction string) error {
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-reaction",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Details:    map[string]any{"commentId": commentID, "reaction": reaction},
+	}) {
+		return nil
+	}
+	_, _, err := pCtx.ClientV3.Reactions.CreateIssueCommentReaction(WithOperation(ctx, "create-reaction"), *pCtx.Owner, *pCtx.Repository, commentID, reaction)
+	return classify(errors.Wrap(err, "failed to react to comment"))
+}
+
+// CommentOnPullRequest posts a comment on the given pull request.
+func (g *Controller) CommentOnPullRequest(ctx context.Context, pCtx *promotion.Context, number int, body string) error {
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-comment",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Details:    map[string]any{"number": number, "body": body},
+	}) {
+		return nil
+	}
+	_, _, err := pCtx.ClientV3.Issues.CreateComment(WithOperation(ctx, "create-comment"), *pCtx.Owner, *pCtx.Repository, number, &github.IssueComment{Body: &body})
+	return classify(errors.Wrap(err, "failed to comment on pull request"))
+}
+
+// IsOnHold reports whether the promotion request carries the hold label.
+func (g *Controller) IsOnHold(ctx context.Context, pCtx *promotion.Context) (bool, error) {
+	if pCtx.PullRequest == nil {
+		return false, nil
+	}
+	labels := pCtx.PullRequest.Labels
+	// Pull requests embedded in event payloads lack their labels
+	if labels == nil {
+		var err error
+		if labels, _, err = pCtx.ClientV3.Issues.ListLabelsByIssue(WithOperation(ctx, "list-labels"), *pCtx.Owner, *pCtx.Repository, pCtx.PullRequest.GetNumber(), &github.ListOptions{PerPage: 100}); err != nil {
+			return false, classify(errors.Wrap(err, "failed to list pull request labels"))
+		}
+	}
+	return slices.ContainsFunc(labels, func(label *github.Label) bool {
+		return label.GetName() == config.Promotion.ChatOps.HoldLabel
+	}), nil
+}
+
+// SetHold adds or removes the hold label of the promotion request.
+func (g *Controller) SetHold(ctx context.Context, pCtx *promotion.Context, held bool) error {
+	number, label := pCtx.PullRequest.GetNumber(), config.Promotion.ChatOps.HoldLabel
+	action := "remove-label"
+	if held {
+		action = "add-label"
+	}
+	if g.planned(ctx, models.PlannedAction{
+		Action:     action,
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		Details:    map[string]any{"number": number, "label": label},
+	}) {
+		return nil
+	}
+	if held {
+		_, _, err := pCtx.ClientV3.Issues.AddLabelsToIssue(WithOperation(ctx, action), *pCtx.Owner, *pCtx.Repository, number, []string{label})
+		return classify(errors.Wrap(err, "failed to add hold label"))
+	}
+	resp, err := pCtx.ClientV3.Issues.RemoveLabelForIssue(WithOperation(ctx, action), *pCtx.Owner, *pCtx.Repository, number, label)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// The promotion request was not on hold
+		return nil
+	}
+	return classify(errors.Wrap(err, "failed to remove hold label"))
+}
+
 // CommitStatus is a type to represent the commit status.
 type CommitStatus string
 
@@ -383,6 +834,13 @@ const (
 	CheckRunStatusQueued CheckRunStatus = "queued"
 )
 
+const (
+	// PromotionCheckRunExternalID is the external ID of the check runs reporting promotion feedback, identifying them in check_run events.
+	PromotionCheckRunExternalID = "gh-promotion-app"
+	// RetryPromotionAction is the identifier of the check run button requesting a promotion retry.
+	RetryPromotionAction = "retry-promotion"
+)
+
 // CheckRunConclusion is a type to represent the check run conclusion.
 type CheckRunConclusion string
 
@@ -404,7 +862,8 @@ const (
 )
 
 // SendPromotionFeedbackCommitStatus sends a commit status to the head commit of the promotion request.
-func (g *Controller) SendPromotionFeedbackCommitStatus(bus *promotion.Bus, commitStatus CommitStatus) error {
+// The statusContext supports the {source}, {target} and {progress} placeholders.
+func (g *Controller) SendPromotionFeedbackCommitStatus(ctx context.Context, bus *promotion.Bus, commitStatus CommitStatus, statusContext string) error {
 	// Validate required fields
 	if bus == nil {
 		return errors.New("promotion bus is nil")
@@ -414,7 +873,7 @@ func (g *Controller) SendPromotionFeedbackCommitStatus(bus *promotion.Bus, commi
 	feedbackLogger := pCtx.Logger.WithGroup("feedback:commit-status")
 
 	// Process and filter invalid feedback requests
-	msg, contextValue := g.processPromotionFeedback(bus, feedbackLogger, config.Promotion.Feedback.CommitStatus.Context)
+	msg, contextValue := g.processPromotionFeedback(bus, feedbackLogger, statusContext)
 	if msg == nil || contextValue == nil {
 		return promotion.NewInternalError("invalid feedback request. callee: processPromotionFeedback")
 	}
@@ -429,7 +888,16 @@ func (g *Controller) SendPromotionFeedbackCommitStatus(bus *promotion.Bus, commi
 	feedbackLogger.Debug("sending commit status",
 		slog.String("status", string(commitStatus)), slog.String("context", *contextValue), slog.String("msg", *msg),
 		slog.String("eventType", fmt.Sprintf("%T", pCtx.EventType)), slog.Any("error", bus.Error))
-	_, resp, err := pCtx.ClientV3.Repositories.CreateStatus(g.ctx, *pCtx.Owner, *pCtx.Repository, *pCtx.HeadSHA, status)
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-commit-status",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		SHA:        *pCtx.HeadSHA,
+		Details:    map[string]any{"context": *contextValue, "state": string(commitStatus), "description": *msg},
+	}) {
+		return nil
+	}
+	_, resp, err := pCtx.ClientV3.Repositories.CreateStatus(WithOperation(ctx, "create-commit-status"), *pCtx.Owner, *pCtx.Repository, *pCtx.HeadSHA, status)
 
 	if err != nil {
 		var body []byte
@@ -437,7 +905,7 @@ func (g *Controller) SendPromotionFeedbackCommitStatus(bus *promotion.Bus, commi
 			body, _ = io.ReadAll(resp.Body)
 		}
 		feedbackLogger.Error("failed to send commit status", slog.Any("error", err), slog.String("body", string(body)))
-		return errors.Wrapf(err, "failed to create commit status. status: %s, body: %s", status, body)
+		return classify(errors.Wrapf(err, "failed to create commit status. status: %s, body: %s", status, body))
 	}
 	feedbackLogger.Debug("successfully sent commit status", slog.Any("status", status.String()), slog.Any("sha", *pCtx.HeadSHA))
 
@@ -448,7 +916,8 @@ func (g *Controller) SendPromotionFeedbackCommitStatus(bus *promotion.Bus, commi
 var checkRunTemplate string
 
 // SendPromotionFeedbackCheckRun sends a check run to the head commit of the promotion request.
-func (g *Controller) SendPromotionFeedbackCheckRun(bus *promotion.Bus, conclusion CheckRunConclusion) error {
+// The name supports the {source}, {target} and {progress} placeholders.
+func (g *Controller) SendPromotionFeedbackCheckRun(ctx context.Context, bus *promotion.Bus, conclusion CheckRunConclusion, name string) error {
 	// Validate required fields
 	if bus == nil {
 		return errors.New("promotion bus is nil")
@@ -458,7 +927,7 @@ func (g *Controller) SendPromotionFeedbackCheckRun(bus *promotion.Bus, conclusio
 	feedbackLogger := pCtx.Logger.WithGroup("feedback:check-run")
 
 	// Process and filter invalid feedback requests
-	msg, nameValue := g.processPromotionFeedback(bus, feedbackLogger, config.Promotion.Feedback.CheckRun.Name)
+	msg, nameValue := g.processPromotionFeedback(bus, feedbackLogger, name)
 	if msg == nil || nameValue == nil {
 		feedbackLogger.Error("invalid feedback request", slog.String("callee", "processPromotionFeedback"))
 		return promotion.NewInternalError("invalid feedback request")
@@ -486,7 +955,8 @@ func (g *Controller) SendPromotionFeedbackCheckRun(bus *promotion.Bus, conclusio
 
 	metadata := map[string]any{
 		"promotion": map[string]any{
-			"path": pCtx.Promoter.Stages,
+			"path":  pCtx.Promoter.Stages,
+			"graph": pCtx.Promoter.Targets,
 		},
 	}
 
@@ -511,6 +981,7 @@ func (g *Controller) SendPromotionFeedbackCheckRun(bus *promotion.Bus, conclusio
 	checkRunOpts := github.CreateCheckRunOptions{
 		Name:        *nameValue,
 		HeadSHA:     *pCtx.HeadSHA,
+		ExternalID:  new(PromotionCheckRunExternalID),
 		Status:      new(string(CheckRunStatusCompleted)),
 		Conclusion:  new(string(conclusion)),
 		CompletedAt: &github.Timestamp{Time: now},
@@ -520,19 +991,35 @@ func (g *Controller) SendPromotionFeedbackCheckRun(bus *promotion.Bus, conclusio
 			Text:    textMessage,
 		},
 	}
+	if conclusion != CheckRunConclusionSuccess && bus.EventStatus != promotion.Pending && pCtx.PullRequest.GetState() != "closed" {
+		checkRunOpts.Actions = []*github.CheckRunAction{{
+			Label:       "Retry promotion",
+			Description: "Re-evaluate and retry the fast-forward",
+			Identifier:  RetryPromotionAction,
+		}}
+	}
 
 	feedbackLogger.Debug("creating check-run...",
 		slog.String("conclusion", string(conclusion)), slog.String("context", *nameValue), slog.String("msg", *msg),
 		slog.String("eventType", fmt.Sprintf("%T", pCtx.EventType)), slog.Any("error", bus.Error))
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-check-run",
+		Owner:      *pCtx.Owner,
+		Repository: *pCtx.Repository,
+		SHA:        *pCtx.HeadSHA,
+		Details:    map[string]any{"name": *nameValue, "conclusion": string(conclusion), "title": *msg},
+	}) {
+		return nil
+	}
 
-	_, resp, err := pCtx.ClientV3.Checks.CreateCheckRun(g.ctx, *pCtx.Owner, *pCtx.Repository, checkRunOpts)
+	_, resp, err := pCtx.ClientV3.Checks.CreateCheckRun(WithOperation(ctx, "create-check-run"), *pCtx.Owner, *pCtx.Repository, checkRunOpts)
 	if err != nil {
 		var body []byte
 		if resp != nil && resp.Body != nil {
 			body, _ = io.ReadAll(resp.Body)
 		}
 		feedbackLogger.Error("failed to create check-run", slog.Any("error", err), slog.String("body", string(body)))
-		return errors.Wrapf(err, "failed to create check-run. conclusion: %s, body: %s", conclusion, body)
+		return classify(errors.Wrapf(err, "failed to create check-run. conclusion: %s, body: %s", conclusion, body))
 	}
 	feedbackLogger.Debug("successfully created check-run", slog.Any("conclusion", conclusion), slog.Any("sha", *pCtx.HeadSHA))
 	return nil
@@ -564,8 +1051,8 @@ func (g *Controller) processPromotionFeedback(bus *promotion.Bus, logger *slog.L
 	}
 
 	// Infer the commit status from the provided promotion.Bus and error
-	stages, index := pCtx.Promoter.Stages, pCtx.Promoter.StageIndex(*pCtx.HeadRef)
-	progress := fmt.Sprintf("%d/%d", index+1, len(stages))
+	// Progress counts levels rather than stages, so that stages fanning out of the same stage progress alike
+	progress := fmt.Sprintf("%d/%d", pCtx.Promoter.Level(*pCtx.HeadRef)+1, pCtx.Promoter.Levels())
 
 	// Local placeholders
 	placeholders := map[string]string{
@@ -629,11 +1116,21 @@ func (g *Controller) EmptyCommitOnBranch(ctx context.Context, clients *Client, c
 		"qualifiedName": *createCommitOnBranchInput.Branch.BranchName,
 	}
 
-	if err := clients.V4.Query(ctx, &query, variables); err != nil {
-		return "", errors.Wrap(err, "EmptyCommitOnBranch: failed create empty commit on branch")
+	if err := clients.V4.Query(WithOperation(ctx, "get-branch-head"), &query, variables); err != nil {
+		return "", classify(errors.Wrap(err, "EmptyCommitOnBranch: failed create empty commit on branch"))
 	}
 
 	createCommitOnBranchInput.ExpectedHeadOid = query.Repository.Ref.Target.Oid
+	if g.planned(ctx, models.PlannedAction{
+		Action:     "create-commit",
+		Owner:      parts[0],
+		Repository: parts[1],
+		Ref:        string(*createCommitOnBranchInput.Branch.BranchName),
+		SHA:        string(createCommitOnBranchInput.ExpectedHeadOid),
+		Details:    map[string]any{"headline": string(createCommitOnBranchInput.Message.Headline)},
+	}) {
+		return "", nil
+	}
 	var mutation struct {
 		CreateCommitOnBranch struct {
 			Commit struct {
@@ -643,8 +1140,8 @@ func (g *Controller) EmptyCommitOnBranch(ctx context.Context, clients *Client, c
 		} `graphql:"createCommitOnBranch(input: $input)"`
 	}
 
-	if err := clients.V4.Mutate(ctx, &mutation, createCommitOnBranchInput, nil); err != nil {
-		return "", errors.Wrap(err, "failed to create commit on branch")
+	if err := clients.V4.Mutate(WithOperation(ctx, "create-commit"), &mutation, createCommitOnBranchInput, nil); err != nil {
+		return "", classify(errors.Wrap(err, "failed to create commit on branch"))
 	}
 	return string(mutation.CreateCommitOnBranch.Commit.Oid), nil
 }
@@ -656,7 +1153,7 @@ func EmptyCommitOnBranchWithDefaultClient(ctx context.Context, req githubv4.Crea
 		return "", err
 	}
 	var clients *Client
-	if clients, err = ctl.GetGitHubClients(nil); err != nil {
+	if clients, err = ctl.GetGitHubClients(ctx, nil); err != nil {
 		return "", err
 	}
 	return ctl.EmptyCommitOnBranch(ctx, clients, req)
@@ -683,9 +1180,15 @@ type ghaitTokenSource struct {
 	logger         *slog.Logger
 }
 
+// Token refreshes the installation token using the long-lived controller context, as it is called by the oauth2 transport.
 func (s *ghaitTokenSource) Token() (*oauth2.Token, error) {
+	return s.token(s.ctx)
+}
+
+// token obtains a new installation token bound to the given context.
+func (s *ghaitTokenSource) token(ctx context.Context) (*oauth2.Token, error) {
 	s.logger.Debug("refreshing installation token...", slog.Int64("installationID", s.installationID))
-	t, err := s.ghait.NewInstallationToken(s.ctx, s.installationID, nil)
+	t, err := s.ghait.NewInstallationToken(ctx, s.installationID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("ghait token refresh: %w", err)
 	}
@@ -0,0 +1,51 @@
+package github
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/pkg/errors"
+)
+
+// ErrNoPromotionRequest is returned when no open promotion request matches the head SHA of the promotion context, e.g.
+// for status and check events on commits that are not promoted. It is classified as invalid input, so that it is not retried.
+var ErrNoPromotionRequest = errors.New("no matching promotion request found")
+
+// classify maps a GitHub API error to its promotion.ErrorKind: rate limits, 5xx responses and network failures
+// are transient, while authentication failures, missing resources and rejected mutations are permanent.
+func classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *promotion.ClassifiedError
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	var (
+		rateLimitErr *github.RateLimitError
+		abuseErr     *github.AbuseRateLimitError
+		respErr      *github.ErrorResponse
+		netErr       net.Error
+	)
+	switch {
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseErr):
+		return promotion.NewError(promotion.KindTransient, err)
+	case errors.As(err, &respErr) && respErr.Response != nil:
+		switch code := respErr.Response.StatusCode; {
+		case code == http.StatusTooManyRequests, code >= http.StatusInternalServerError:
+			return promotion.NewError(promotion.KindTransient, err)
+		case code == http.StatusUnauthorized, code == http.StatusForbidden:
+			return promotion.NewError(promotion.KindAuth, err)
+		case code == http.StatusConflict, code == http.StatusUnprocessableEntity:
+			return promotion.NewError(promotion.KindConflict, err)
+		case code == http.StatusBadRequest, code == http.StatusNotFound:
+			return promotion.NewError(promotion.KindInvalidInput, err)
+		}
+	case errors.As(err, &netErr):
+		return promotion.NewError(promotion.KindTransient, err)
+	}
+	return err
+}
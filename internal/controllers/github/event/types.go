@@ -19,14 +19,35 @@ const (
 	PullRequestReview Type = "pull_request_review"
 	// CheckSuite represents a check suite event type.
 	CheckSuite Type = "check_suite"
+	// CheckRun represents a check run event type.
+	CheckRun Type = "check_run"
+	// MergeGroup represents a merge group event type.
+	MergeGroup Type = "merge_group"
+	// IssueComment represents an issue comment event type.
+	IssueComment Type = "issue_comment"
 	// DeploymentStatus represents a deployment status event type.
 	DeploymentStatus Type = "deployment_status"
 	// Status represents a status event type.
 	Status Type = "status"
 	// WorkflowRun represents a workflow run event type.
 	WorkflowRun Type = "workflow_run"
+	// Create represents a ref creation event type.
+	Create Type = "create"
+	// Delete represents a ref deletion event type.
+	Delete Type = "delete"
+	// Installation represents an app installation event type.
+	Installation Type = "installation"
+	// InstallationRepositories represents an app installation repositories event type.
+	InstallationRepositories Type = "installation_repositories"
+	// CustomPropertyValues represents a repository custom property values event type.
+	CustomPropertyValues Type = "custom_property_values"
 )
 
+// IsInstallationEvent returns true if the event type concerns the app installation rather than a single repository.
+func IsInstallationEvent(eventType Type) bool {
+	return eventType == Installation || eventType == InstallationRepositories
+}
+
 // IsEnabled returns true if the event type is enabled.
 func IsEnabled(eventType Type) bool {
 	return slices.Contains(config.Promotion.Events, string(eventType))
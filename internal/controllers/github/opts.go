@@ -48,3 +48,10 @@ func WithWebhookSecret(secret *validation.WebhookSecret) GHOption {
 		a.WebhookSecret = secret
 	}
 }
+
+// WithClientCache sets the cache of installation clients, e.g. to share it across controllers or attach CacheHooks.
+func WithClientCache(cache *ClientCache) GHOption {
+	return func(a *Controller) {
+		a.clientCache = cache
+	}
+}
@@ -0,0 +1,196 @@
+package github
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/pkg/errors"
+)
+
+type operationKey struct{}
+
+// WithOperation names the GitHub API operation performed with the returned context, selecting its retry policy.
+func WithOperation(ctx context.Context, operation string) context.Context {
+	return context.WithValue(ctx, operationKey{}, operation)
+}
+
+// operationFromContext returns the operation name carried by the context, if any.
+func operationFromContext(ctx context.Context) string {
+	operation, _ := ctx.Value(operationKey{}).(string)
+	return operation
+}
+
+// RetryPolicies holds the default retry policy and its per-operation overrides.
+type RetryPolicies struct {
+	Default    config.RetryPolicy
+	Operations map[string]config.RetryPolicy
+}
+
+// policy returns the retry policy of the given operation, falling back to the default policy for unset fields.
+func (p RetryPolicies) policy(operation string) config.RetryPolicy {
+	policy := p.Default
+	override, found := p.Operations[operation]
+	if !found {
+		return policy
+	}
+	if override.MaxAttempts > 0 {
+		policy.MaxAttempts = override.MaxAttempts
+	}
+	if override.InitialBackoff > 0 {
+		policy.InitialBackoff = override.InitialBackoff
+	}
+	if override.MaxBackoff > 0 {
+		policy.MaxBackoff = override.MaxBackoff
+	}
+	if override.Budget > 0 {
+		policy.Budget = override.Budget
+	}
+	if override.Idempotent != nil {
+		policy.Idempotent = override.Idempotent
+	}
+	return policy
+}
+
+// retryTransport retries requests failing with transient errors, using exponential backoff with full jitter within a bounded budget.
+//
+// Idempotent requests are retried on 500, 502, 503 and 504 responses and on network errors.
+// Non-idempotent requests are only retried when GitHub cannot have processed them: on 503 responses and on failures to connect.
+type retryTransport struct {
+	base     http.RoundTripper
+	policies RetryPolicies
+	logger   *slog.Logger
+}
+
+// NewRetryTransport wraps the given transport with retries governed by the given policies.
+func NewRetryTransport(base http.RoundTripper, policies RetryPolicies, logger *slog.Logger) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if logger == nil {
+		logger = helpers.NewNoopLogger()
+	}
+	return &retryTransport{base: base, policies: policies, logger: logger}
+}
+
+// RoundTrip sends the request, retrying it according to the policy of its operation.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	operation := operationFromContext(ctx)
+	policy := t.policies.policy(operation)
+	idempotent := isIdempotent(req.Method)
+	if policy.Idempotent != nil {
+		idempotent = *policy.Idempotent
+	}
+	// The body must be replayable to be retried
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		policy.MaxAttempts = 1
+	}
+
+	logger := t.logger.With(slog.String("operation", operation), slog.String("method", req.Method), slog.String("url", req.URL.String()))
+	started := time.Now()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		if !retryable(resp, err, idempotent) || attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		delay := backoff(policy, attempt, resp)
+		if policy.Budget > 0 && time.Since(started)+delay > policy.Budget {
+			logger.Warn("retry budget exhausted", slog.Int("attempt", attempt), slog.Duration("elapsed", time.Since(started)))
+			return resp, err
+		}
+		attrs := []any{slog.Int("attempt", attempt), slog.Int("maxAttempts", policy.MaxAttempts), slog.Duration("backoff", delay)}
+		if err != nil {
+			attrs = append(attrs, slog.Any("error", err))
+		} else {
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			// Release the connection of the discarded response
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		logger.Warn("retrying GitHub request", attrs...)
+
+		if err = sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the jittered delay before the retry following the given attempt, honouring any Retry-After header.
+func backoff(policy config.RetryPolicy, attempt int, resp *http.Response) time.Duration {
+	ceiling := policy.InitialBackoff << (attempt - 1)
+	if ceiling <= 0 || (policy.MaxBackoff > 0 && ceiling > policy.MaxBackoff) {
+		ceiling = policy.MaxBackoff
+	}
+	var delay time.Duration
+	if ceiling > 0 {
+		delay = rand.N(ceiling + 1) //nolint:gosec // jitter does not require a secure source
+	}
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			delay = max(delay, time.Duration(seconds)*time.Second)
+		}
+	}
+	return delay
+}
+
+// isIdempotent reports whether requests with the given method can safely be repeated.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryable reports whether the outcome of a request is transient and the request safe to repeat.
+func retryable(resp *http.Response, err error, idempotent bool) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		if !idempotent {
+			// Only retry when the request never reached GitHub
+			var opErr *net.OpError
+			return errors.Is(err, syscall.ECONNREFUSED) || (errors.As(err, &opErr) && opErr.Op == "dial")
+		}
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusServiceUnavailable:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return idempotent
+	}
+	return false
+}
+
+// sleep waits for the given duration, or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
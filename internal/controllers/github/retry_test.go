@@ -0,0 +1,100 @@
+package github_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryTransport(t *testing.T) {
+	policies := github.RetryPolicies{
+		Default: config.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond, Budget: time.Second},
+		Operations: map[string]config.RetryPolicy{
+			"update-ref": {Idempotent: new(true)},
+			"no-retry":   {MaxAttempts: 1},
+		},
+	}
+
+	// server fails the first failures requests with the given status and Retry-After header, and records the bodies it received
+	server := func(t *testing.T, failures int32, status int, retryAfter string) (*httptest.Server, *atomic.Int32, func() []string) {
+		var (
+			calls  atomic.Int32
+			mu     sync.Mutex
+			bodies []string
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			bodies = append(bodies, string(body))
+			mu.Unlock()
+			if calls.Add(1) <= failures {
+				w.Header().Set("Retry-After", retryAfter)
+				w.WriteHeader(status)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &calls, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return bodies
+		}
+	}
+
+	send := func(t *testing.T, url, method, operation string) int {
+		req, err := http.NewRequestWithContext(github.WithOperation(context.Background(), operation), method, url, strings.NewReader(`{"sha":"abc"}`))
+		require.NoError(t, err)
+		resp, err := (&http.Client{Transport: github.NewRetryTransport(nil, policies, nil)}).Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		operation string
+		failures  int32
+		status    int
+		wantCode  int
+		wantCalls int32
+	}{
+		{name: "idempotent retried on 502", method: http.MethodGet, failures: 2, status: http.StatusBadGateway, wantCode: http.StatusOK, wantCalls: 3},
+		{name: "attempts bounded", method: http.MethodGet, failures: 5, status: http.StatusGatewayTimeout, wantCode: http.StatusGatewayTimeout, wantCalls: 3},
+		{name: "client errors not retried", method: http.MethodGet, failures: 1, status: http.StatusNotFound, wantCode: http.StatusNotFound, wantCalls: 1},
+		{name: "non-idempotent not retried on 502", method: http.MethodPost, failures: 1, status: http.StatusBadGateway, wantCode: http.StatusBadGateway, wantCalls: 1},
+		{name: "non-idempotent retried on 503", method: http.MethodPost, failures: 1, status: http.StatusServiceUnavailable, wantCode: http.StatusOK, wantCalls: 2},
+		{name: "operation marked idempotent", method: http.MethodPatch, operation: "update-ref", failures: 1, status: http.StatusBadGateway, wantCode: http.StatusOK, wantCalls: 2},
+		{name: "operation retries disabled", method: http.MethodGet, operation: "no-retry", failures: 1, status: http.StatusServiceUnavailable, wantCode: http.StatusServiceUnavailable, wantCalls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, calls, bodies := server(t, tt.failures, tt.status, "0")
+			assert.Equal(t, tt.wantCode, send(t, srv.URL, tt.method, tt.operation))
+			assert.Equal(t, tt.wantCalls, calls.Load())
+			// The body is replayed on every attempt
+			for _, body := range bodies() {
+				assert.JSONEq(t, `{"sha":"abc"}`, body)
+			}
+		})
+	}
+
+	t.Run("budget", func(t *testing.T) {
+		srv, calls, _ := server(t, 5, http.StatusServiceUnavailable, "1")
+		policies.Operations["slow"] = config.RetryPolicy{MaxAttempts: 10, Budget: 500 * time.Millisecond}
+		assert.Equal(t, http.StatusServiceUnavailable, send(t, srv.URL, http.MethodGet, "slow"))
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -6,14 +6,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
+	"time"
 
 	uGitHub "github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
 	"github.com/isometry/gh-promotion-app/internal/controllers/aws"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github"
-	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/handler/processor"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/idempotency"
+	"github.com/isometry/gh-promotion-app/internal/lock"
 	"github.com/isometry/gh-promotion-app/internal/models"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
 	"github.com/isometry/gh-promotion-app/internal/validation"
@@ -28,10 +32,10 @@ type Handler struct {
 	githubController *github.Controller
 	awsController    *aws.Controller
 
-	preProcessors      []processor.Processor
-	processors         map[event.Type][]processor.Processor
-	postProcessors     []processor.Processor
-	feedbackProcessors []processor.Processor
+	locker      lock.Locker
+	idempotency idempotency.Store // nil if deduplication is disabled
+	deps        processor.Dependencies
+	pipeline    *pipeline // validated at startup; only used to resolve the supported event types
 
 	ctx               context.Context
 	logger            *slog.Logger
@@ -40,6 +44,8 @@ type Handler struct {
 	ghToken           string
 	lambdaPayloadType string
 	webhookSecret     *validation.WebhookSecret
+	skipSignature     bool
+	clientCache       *github.ClientCache
 }
 
 // NewPromotionHandler creates a new promotion handler instance.
@@ -49,9 +55,26 @@ func NewPromotionHandler(options ...Option) (*Handler, error) {
 		opt(_inst)
 	}
 
+	if graph := config.Promotion.DefaultGraph; graph != "" {
+		// Invalid default graphs are rejected upfront rather than silently falling back to the default stages
+		if _, err := promotion.NewGraphPromoter("", graph); err != nil {
+			return nil, errors.Wrap(err, "invalid default promotion graph")
+		}
+	}
+
+	for name, stage := range config.Promotion.Stages {
+		// Unknown roles would deny every ChatOps command on promotions into the stage
+		if !promotion.IsRole(stage.MinRole) {
+			return nil, errors.Errorf("stages.%s.minRole: unknown role %q", name, stage.MinRole)
+		}
+	}
+
 	if _inst.ctx == nil {
 		_inst.ctx = context.Background()
 	}
+	if _inst.locker == nil {
+		_inst.locker = lock.NewMemory()
+	}
 
 	awsCtl, err := aws.NewController(
 		aws.WithLogger(_inst.logger.With("component", "aws-controller")),
@@ -66,61 +89,131 @@ func NewPromotionHandler(options ...Option) (*Handler, error) {
 		github.WithAuthMode(_inst.authMode),
 		github.WithToken(_inst.ghToken),
 		github.WithAWSController(awsCtl),
-		github.WithWebhookSecret(_inst.webhookSecret))
+		github.WithWebhookSecret(_inst.webhookSecret),
+		github.WithClientCache(_inst.clientCache))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create the GitHubController instance")
 	}
 	_inst.awsController = awsCtl
 	_inst.githubController = githubController
 
-	// Defined processors
-	_inst.preProcessors = []processor.Processor{
-		processor.NewDynamicPromotionPreProcessor(_inst.githubController),
-	}
-	_inst.processors = map[event.Type][]processor.Processor{
-		event.Push:              {processor.NewPushEventProcessor(_inst.githubController)},
-		event.PullRequest:       {processor.NewPullRequestEventProcessor(_inst.githubController)},
-		event.PullRequestReview: {processor.NewPullRequestReviewEventProcessor(_inst.githubController)},
-		event.CheckSuite:        {processor.NewCheckSuiteEventProcessor(_inst.githubController)},
-		event.DeploymentStatus:  {processor.NewDeploymentStatusEventProcessor(_inst.githubController)},
-		event.Status:            {processor.NewStatusEventProcessor(_inst.githubController)},
-		event.WorkflowRun:       {processor.NewWorkflowRunEventProcessor(_inst.githubController)},
+	// Compose the processor pipeline from the configuration
+	_inst.deps = processor.Dependencies{
+		GitHubController: _inst.githubController,
+		AWSController:    _inst.awsController,
 	}
-	_inst.postProcessors = []processor.Processor{
-		processor.NewFastForwarderPostProcessor(_inst.githubController),
-		processor.NewS3UploaderPostProcessor(_inst.awsController),
+	if _inst.pipeline, err = _inst.newPipeline(); err != nil {
+		return nil, errors.Wrap(err, "failed to compose the processor pipeline")
 	}
-	_inst.feedbackProcessors = []processor.Processor{
-		processor.NewCommitStatusFeedbackProcessor(_inst.githubController),
-		processor.NewCheckRunFeedbackProcessor(_inst.githubController),
+
+	if _inst.idempotency == nil && config.Idempotency.Enabled {
+		if _inst.idempotency, err = newIdempotencyStore(awsCtl); err != nil {
+			return nil, errors.Wrap(err, "failed to create the idempotency store")
+		}
 	}
 
 	return _inst, err
 }
 
 // Process processes the incoming request.
-func (h *Handler) Process(body []byte, headers map[string]string) (*promotion.Bus, error) {
-	logger := h.logger
+// The context bounds the whole pipeline; each phase may be further bounded by config.Pipeline.Timeouts.
+func (h *Handler) Process(ctx context.Context, body []byte, headers map[string]string) (*promotion.Bus, error) {
+	bus, err := h.Authenticate(ctx, body, headers)
+	if err != nil {
+		return bus, err
+	}
+	return h.Run(ctx, bus)
+}
 
-	// Authentication & Validation
+// Authenticate validates the incoming request and returns the Bus to be handed over to Run.
+func (h *Handler) Authenticate(ctx context.Context, body []byte, headers map[string]string) (*promotion.Bus, error) {
 	authValidatorProcessor := processor.NewAuthValidatorProcessor(h.githubController)
-	bus, err := processor.Process(logger, &processor.AuthRequest{
-		Body:            body,
-		Headers:         headers,
-		EventProcessors: h.processors,
+	bus, err := runPhase(ctx, h.logger, "auth", config.Pipeline.Timeouts.Auth, &processor.AuthRequest{
+		Body:                    body,
+		Headers:                 headers,
+		EventProcessors:         h.pipeline.events,
+		SkipSignatureValidation: h.skipSignature,
 	}, authValidatorProcessor)
 	if err != nil {
-		logger.Error("failed to authenticate request", slog.Any("error", err))
+		h.logger.Error("failed to authenticate request", slog.Any("error", err))
+	}
+	return bus, err
+}
+
+// Run runs the pre, event, post and feedback phases against an authenticated Bus.
+// Deliveries already processed are answered with their recorded outcome unless config.Idempotency.ForceReprocess is set.
+func (h *Handler) Run(ctx context.Context, bus *promotion.Bus) (*promotion.Bus, error) {
+	if config.Global.DryRun {
+		// Dry runs are neither answered from nor recorded in the idempotency store
+		return h.dryRun(ctx, bus)
+	}
+	if h.idempotency == nil || bus.DeliveryID == "" {
+		return h.run(ctx, bus)
+	}
+
+	logger := h.logger.With(slog.String("deliveryID", bus.DeliveryID))
+	if !config.Idempotency.ForceReprocess {
+		record, err := h.idempotency.Get(ctx, bus.DeliveryID)
+		switch {
+		case err == nil:
+			logger.Info("skipping already processed delivery", slog.Time("processedAt", record.ProcessedAt), slog.String("status", record.Status))
+			bus.EventStatus = promotion.EventStatus(record.Status)
+			bus.Response = record.Response
+			return bus, nil
+		case !errors.Is(err, idempotency.ErrNotFound):
+			// Processing the delivery again is preferable to dropping it
+			logger.Warn("failed to look up delivery", slog.Any("error", err))
+		}
+	}
+
+	bus, err := h.run(ctx, bus)
+	if err != nil {
+		// Failed deliveries are not recorded so that redeliveries are processed again
 		return bus, err
 	}
+	if err := h.idempotency.Put(ctx, idempotency.Record{
+		DeliveryID:  bus.DeliveryID,
+		EventType:   string(bus.EventType),
+		Status:      string(bus.EventStatus),
+		Response:    bus.Response,
+		ProcessedAt: time.Now().UTC(),
+	}); err != nil {
+		logger.Warn("failed to record delivery", slog.Any("error", err))
+	}
+	return bus, nil
+}
+
+// dryRun runs the pipeline while recording the GitHub mutations in a plan returned with the response.
+func (h *Handler) dryRun(ctx context.Context, bus *promotion.Bus) (*promotion.Bus, error) {
+	plan := new(promotion.Plan)
+	bus.Plan = plan
+	bus, err := h.run(promotion.ContextWithPlan(ctx, plan), bus)
+	if bus == nil {
+		return bus, err
+	}
+	bus.Response.Plan = plan.Actions()
+	h.logger.Info("dry-run plan", slog.String("deliveryID", bus.DeliveryID), slog.Any("plan", bus.Response.Plan))
+	return bus, err
+}
+
+func (h *Handler) run(ctx context.Context, bus *promotion.Bus) (*promotion.Bus, error) {
+	timeouts := config.Pipeline.Timeouts
+	pipe, err := h.newPipeline()
+	if err != nil {
+		return bus, promotion.NewInternalErrorf("failed to compose the processor pipeline. error: %v", err)
+	}
 
-	logger = logger.With(slog.Any("context", bus.Context))
+	logger := h.logger.With(slog.Any("context", bus.Context))
 	logger.Info("processing event...")
 
+	// Processors lock the base ref once it is known; the lock is held until the event is fully processed
+	bus.Locker = h.locker
+	defer bus.Unlock()
+
 	// Pre-processors
 	// @NOTE The processors sections is intentionally not DRY to allow for flexibility in the future
 	logger.Debug("launching pre-processors...")
-	bus, err = processor.Process(logger, bus, h.preProcessors...)
+	bus, err = runPhase(ctx, logger, "pre", timeouts.Pre, bus, pipe.pre...)
 	if err != nil {
 		logger.Error("failed to pre-process event", slog.Any("error", err))
 		return bus, err
@@ -132,8 +225,8 @@ func (h *Handler) Process(body []byte, headers map[string]string) (*promotion.Bu
 
 	// Processors
 	logger.Debug("launching processors...")
-	eventProcessors := h.processors[bus.EventType]
-	bus, err = processor.Process(logger, bus, eventProcessors...)
+	eventProcessors := pipe.events[bus.EventType]
+	bus, err = runPhase(ctx, logger, "event", timeouts.Event, bus, eventProcessors...)
 	if err != nil {
 		logger.Error("failed to post-process event", slog.Any("error", err))
 		return bus, err
@@ -143,30 +236,109 @@ func (h *Handler) Process(body []byte, headers map[string]string) (*promotion.Bu
 		return bus, nil
 	}
 
+	bus, err = h.promote(ctx, logger, pipe, bus)
+	if bus == nil || len(bus.Forks) == 0 {
+		return bus, err
+	}
+
+	// Events promoting into several stages, e.g. pushes to a stage fanning out, carry a fork of the bus for each other
+	// promotion. Forks are promoted one lock at a time; their decision traces are appended to the trace of the bus, and
+	// the first failure is returned.
+	bus.Unlock()
+	for _, fork := range bus.Forks {
+		fork, forkErr := h.promote(ctx, logger.With(slog.Any("fork", fork.Context)), pipe, fork)
+		if fork != nil {
+			fork.Unlock()
+			bus.Trace = append(bus.Trace, fork.Trace...)
+		}
+		if err == nil {
+			err = forkErr
+		}
+		if errors.Is(forkErr, promotion.ErrBudgetExhausted) {
+			break
+		}
+	}
+	bus.Response.Trace = bus.Trace
+	return bus, err
+}
+
+// promote runs the post and feedback phases against a Bus processed by its event processors.
+// Failures of the post phase are reported by the feedback phase, then returned so that the delivery is not recorded
+// and is answered with the status code of their error class.
+func (h *Handler) promote(ctx context.Context, logger *slog.Logger, pipe *pipeline, bus *promotion.Bus) (*promotion.Bus, error) {
+	timeouts := config.Pipeline.Timeouts
+
 	// Post-processors
 	logger.Debug("launching post-processors...")
-	bus, err = processor.Process(logger, bus, h.postProcessors...)
-	if err != nil {
-		logger.Error("failed to process event", slog.Any("error", err))
+	bus, postErr := runPhase(ctx, logger, "post", timeouts.Post, bus, pipe.post...)
+	if postErr != nil {
+		logger.Error("failed to process event", slog.Any("error", postErr))
+		if errors.Is(postErr, promotion.ErrBudgetExhausted) {
+			return bus, postErr
+		}
 	}
-	if bus.EventStatus == promotion.Skipped {
+	// Commands are answered even if the promotion was skipped
+	if bus.EventStatus == promotion.Skipped && bus.Command == nil {
 		logger.Info("skipping event processing")
-		return bus, nil
+		return bus, postErr
 	}
 
 	// Feedback
 	logger.Debug("launching feedback processors...")
-	bus, err = processor.Process(logger, bus, h.feedbackProcessors...)
+	bus, err := runPhase(ctx, logger, "feedback", timeouts.Feedback, bus, pipe.feedback...)
 	if err != nil {
 		logger.Error("failed to process event", slog.Any("error", err))
+		if postErr == nil {
+			return bus, err
+		}
+	}
+
+	return bus, postErr
+}
+
+// newIdempotencyStore creates the idempotency store selected by config.Idempotency.
+func newIdempotencyStore(awsCtl *aws.Controller) (idempotency.Store, error) {
+	cfg := config.Idempotency
+	switch cfg.Store {
+	case config.StoreMemory:
+		return idempotency.NewMemory(cfg.MaxEntries, cfg.TTL), nil
+	case config.StoreFile:
+		return idempotency.NewFile(cfg.Path, cfg.TTL)
+	case config.StoreS3:
+		return idempotency.NewS3(awsCtl, cfg.BucketName, cfg.Prefix, cfg.TTL)
+	default:
+		return nil, fmt.Errorf("unsupported idempotency store: %s", cfg.Store)
+	}
+}
+
+// runPhase runs the processors of a single phase, bounded by the given timeout on top of the request deadline.
+// Once the deadline expires the phase fails with promotion.ErrBudgetExhausted and a retryable 503 response.
+func runPhase(ctx context.Context, logger *slog.Logger, phase string, timeout time.Duration, req any, processors ...processor.Processor) (*promotion.Bus, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	bus, err := processor.Process(ctx, logger, req, processors...)
+	if bus != nil {
+		// Processors replace the response as they go, so the trace is attached once the phase completes
+		bus.Response.Trace = bus.Trace
+	}
+	if ctx.Err() == nil {
 		return bus, err
 	}
 
-	return bus, nil
+	if bus == nil {
+		bus = &promotion.Bus{}
+	}
+	err = fmt.Errorf("%s phase: %w: %w", phase, promotion.ErrBudgetExhausted, ctx.Err())
+	bus.Response = models.Response{Body: err.Error(), StatusCode: http.StatusServiceUnavailable, Trace: bus.Trace}
+	return bus, err
 }
 
 // ProcessEvent processes the incoming EventBridge event.
-func (h *Handler) ProcessEvent(event map[string]any) (*promotion.Bus, error) {
+func (h *Handler) ProcessEvent(ctx context.Context, event map[string]any) (*promotion.Bus, error) {
 	raw, err := json.Marshal(event)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal event. error: %w", err)
@@ -187,7 +359,7 @@ func (h *Handler) ProcessEvent(event map[string]any) (*promotion.Bus, error) {
 		return nil, fmt.Errorf("failed to marshal event details. error: %w", err)
 	}
 
-	return h.Process(rawDetails, headers)
+	return h.Process(ctx, rawDetails, headers)
 }
 
 // GetLambdaPayloadType returns the lambda payload type.
@@ -0,0 +1,216 @@
+package handler_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/idempotency"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.SetDefaults(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// flakyPostProcessor fails its first promotion with a transient error, as a fast-forward rate limited by GitHub would.
+type flakyPostProcessor struct {
+	calls *int
+}
+
+func (p flakyPostProcessor) Name() string { return "flaky" }
+
+func (p flakyPostProcessor) SetLogger(*slog.Logger) {}
+
+func (p flakyPostProcessor) Process(_ context.Context, req any) (*promotion.Bus, error) {
+	bus := req.(*promotion.Bus)
+	*p.calls++
+	if *p.calls == 1 {
+		err := promotion.NewErrorf(promotion.KindTransient, "rate limited")
+		bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
+		return bus, err
+	}
+	bus.Response = models.Response{Body: "Promotion complete", StatusCode: http.StatusOK}
+	bus.EventStatus = promotion.Success
+	return bus, nil
+}
+
+// blockingProcessor blocks until its phase is cancelled, as a processor stalled on an unresponsive upstream API would.
+type blockingProcessor struct{}
+
+func (blockingProcessor) Name() string { return "blocking" }
+
+func (blockingProcessor) SetLogger(*slog.Logger) {}
+
+func (blockingProcessor) Process(ctx context.Context, req any) (*promotion.Bus, error) {
+	<-ctx.Done()
+	return req.(*promotion.Bus), ctx.Err()
+}
+
+// restorePipeline restores the pipeline configuration once the test completes.
+func restorePipeline(t *testing.T) {
+	t.Helper()
+	saved := config.Pipeline
+	t.Cleanup(func() { config.Pipeline = saved })
+}
+
+func TestNewPromotionHandlerPipelineEvents(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		Events        map[string][]config.PipelineStep
+		ExpectedError string
+	}{
+		{
+			Name:   "supported_event_type",
+			Events: map[string][]config.PipelineStep{"check_run": {{Name: "check-run-event"}}},
+		},
+		{
+			Name:   "disabled_event_type",
+			Events: map[string][]config.PipelineStep{"status": {}},
+		},
+		{
+			Name:          "ambiguous_processor_name",
+			Events:        map[string][]config.PipelineStep{"check_run": {{Name: "check-run"}}},
+			ExpectedError: `events.check_run: unknown processor "check-run"`,
+		},
+		{
+			Name:          "misspelt_event_type",
+			Events:        map[string][]config.PipelineStep{"check-run": {{Name: "check-run-event"}}},
+			ExpectedError: "events.check-run: unsupported event type",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			restorePipeline(t)
+			config.Pipeline.Events = tc.Events
+
+			_, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"))
+			if tc.ExpectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.ExpectedError)
+		})
+	}
+}
+
+func TestRunRedeliveryAfterFailedPostPhase(t *testing.T) {
+	var calls int
+	processor.Register("test-flaky", func(processor.Dependencies, ...processor.Option) processor.Processor {
+		return flakyPostProcessor{calls: &calls}
+	})
+	restorePipeline(t)
+	config.Pipeline.Pre = nil
+	config.Pipeline.Events = map[string][]config.PipelineStep{string(event.Push): {}}
+	config.Pipeline.Post = []config.PipelineStep{{Name: "test-flaky"}}
+	config.Pipeline.Feedback = nil
+
+	h, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"),
+		handler.WithIdempotencyStore(idempotency.NewMemory(10, time.Hour)))
+	require.NoError(t, err)
+	deliver := func() (*promotion.Bus, error) {
+		return h.Run(context.Background(), &promotion.Bus{
+			EventType:   event.Push,
+			EventStatus: promotion.Error,
+			DeliveryID:  "delivery",
+			Context:     &promotion.Context{},
+		})
+	}
+
+	// The failed promotion is answered with a retryable error and not recorded
+	bus, err := deliver()
+	require.Error(t, err)
+	assert.True(t, promotion.IsRetryable(err))
+	assert.Equal(t, http.StatusServiceUnavailable, promotion.StatusCode(err))
+	assert.Equal(t, http.StatusServiceUnavailable, bus.Response.StatusCode)
+
+	// The redelivery is processed again
+	bus, err = deliver()
+	require.NoError(t, err)
+	assert.Equal(t, promotion.Success, bus.EventStatus)
+	assert.Equal(t, 2, calls)
+
+	// The successful outcome is recorded
+	bus, err = deliver()
+	require.NoError(t, err)
+	assert.Equal(t, promotion.Success, bus.EventStatus)
+	assert.Equal(t, http.StatusOK, bus.Response.StatusCode)
+	assert.Equal(t, 2, calls)
+}
+
+func TestNewPromotionHandlerStageMinRole(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		MinRole       string
+		ExpectedError string
+	}{
+		{
+			Name:    "base_role",
+			MinRole: "Maintain",
+		},
+		{
+			Name:          "unknown_role",
+			MinRole:       "maintainer",
+			ExpectedError: `stages.staging.minRole: unknown role "maintainer"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			saved := config.Promotion
+			t.Cleanup(func() { config.Promotion = saved })
+			config.Promotion.Stages = map[string]config.Stage{"staging": {MinRole: tc.MinRole}}
+
+			_, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"))
+			if tc.ExpectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.ExpectedError)
+		})
+	}
+}
+
+func TestRunPhaseTimeout(t *testing.T) {
+	processor.Register("test-blocking", func(processor.Dependencies, ...processor.Option) processor.Processor {
+		return blockingProcessor{}
+	})
+	restorePipeline(t)
+	config.Pipeline.Pre = nil
+	config.Pipeline.Events = map[string][]config.PipelineStep{string(event.Push): {}}
+	config.Pipeline.Post = []config.PipelineStep{{Name: "test-blocking"}}
+	config.Pipeline.Feedback = nil
+	config.Pipeline.Timeouts.Post = 10 * time.Millisecond
+
+	h, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"))
+	require.NoError(t, err)
+
+	bus, err := h.Run(context.Background(), &promotion.Bus{
+		EventType:   event.Push,
+		EventStatus: promotion.Error,
+		Context:     &promotion.Context{},
+	})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, promotion.ErrBudgetExhausted)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "post phase")
+	assert.True(t, promotion.IsRetryable(err))
+	assert.Equal(t, http.StatusServiceUnavailable, promotion.StatusCode(err))
+	assert.Equal(t, http.StatusServiceUnavailable, bus.Response.StatusCode)
+	require.NotEmpty(t, bus.Response.Trace)
+	assert.Equal(t, "blocking", bus.Response.Trace[len(bus.Response.Trace)-1].Processor)
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/idempotency"
+	"github.com/isometry/gh-promotion-app/internal/lock"
 	"github.com/isometry/gh-promotion-app/internal/validation"
 )
 
@@ -55,3 +58,34 @@ func WithWebhookSecret(secret string) Option {
 		h.webhookSecret = validation.NewWebhookSecret(secret)
 	}
 }
+
+// WithoutSignatureValidation accepts payloads without a valid webhook signature, e.g. to replay stored payloads offline.
+func WithoutSignatureValidation() Option {
+	return func(h *Handler) {
+		h.skipSignature = true
+	}
+}
+
+// WithLocker sets the Locker used to serialise the processing of events targeting the same base ref.
+// Defaults to an in-process lock; a shared implementation is required to serialise across replicas.
+func WithLocker(locker lock.Locker) Option {
+	return func(h *Handler) {
+		h.locker = locker
+	}
+}
+
+// WithClientCache sets the cache of GitHub installation clients, e.g. to attach github.CacheHooks feeding metrics.
+// Defaults to a cache bounded by config.GitHub.ClientCache.
+func WithClientCache(cache *github.ClientCache) Option {
+	return func(h *Handler) {
+		h.clientCache = cache
+	}
+}
+
+// WithIdempotencyStore sets the store recording the outcome of processed deliveries.
+// Defaults to the store selected by config.Idempotency.
+func WithIdempotencyStore(store idempotency.Store) Option {
+	return func(h *Handler) {
+		h.idempotency = store
+	}
+}
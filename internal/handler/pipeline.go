@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+)
+
+// defaultEventProcessors maps each supported event type to the processors run when the configuration does not override it.
+var defaultEventProcessors = map[event.Type][]config.PipelineStep{
+	event.Push:                     {{Name: "push"}},
+	event.PullRequest:              {{Name: "pull-request"}},
+	event.PullRequestReview:        {{Name: "pull-request-review"}},
+	event.CheckSuite:               {{Name: "check-suite"}},
+	event.CheckRun:                 {{Name: "check-run-event"}},
+	event.MergeGroup:               {{Name: "merge-group"}},
+	event.IssueComment:             {{Name: "issue-comment"}},
+	event.DeploymentStatus:         {{Name: "deployment-status"}},
+	event.Status:                   {{Name: "status"}},
+	event.WorkflowRun:              {{Name: "workflow-run"}},
+	event.Create:                   {{Name: "create"}},
+	event.Delete:                   {{Name: "delete"}},
+	event.CustomPropertyValues:     {{Name: "custom-property-values"}},
+	event.Installation:             {{Name: "installation"}},
+	event.InstallationRepositories: {{Name: "installation-repositories"}},
+}
+
+// supportedEventTypes returns the sorted event types accepted as keys of config.Pipeline.Events.
+func supportedEventTypes() []event.Type {
+	return slices.Sorted(maps.Keys(defaultEventProcessors))
+}
+
+// pipeline holds one instance of each processor referenced by config.Pipeline.
+type pipeline struct {
+	pre      []processor.Processor
+	events   map[event.Type][]processor.Processor
+	post     []processor.Processor
+	feedback []processor.Processor
+}
+
+// newPipeline instantiates the pre, event, post and feedback processors referenced by config.Pipeline.
+// Processors hold per-request state such as their logger, so concurrent requests must not share a pipeline.
+func (h *Handler) newPipeline() (_ *pipeline, err error) {
+	p := new(pipeline)
+	if p.pre, err = processor.NewFromSteps(config.Pipeline.Pre, h.deps); err != nil {
+		return nil, fmt.Errorf("pre: %w", err)
+	}
+
+	eventSteps := maps.Clone(defaultEventProcessors)
+	for eventType, steps := range config.Pipeline.Events {
+		// Event types are matched verbatim, so a misspelt key would register a pipeline that never runs
+		if _, supported := defaultEventProcessors[event.Type(eventType)]; !supported {
+			return nil, fmt.Errorf("events.%s: unsupported event type. supported event types: %v", eventType, supportedEventTypes())
+		}
+		eventSteps[event.Type(eventType)] = steps
+	}
+	p.events = make(map[event.Type][]processor.Processor, len(eventSteps))
+	for eventType, steps := range eventSteps {
+		if p.events[eventType], err = processor.NewFromSteps(steps, h.deps); err != nil {
+			return nil, fmt.Errorf("events.%s: %w", eventType, err)
+		}
+	}
+
+	if p.post, err = processor.NewFromSteps(config.Pipeline.Post, h.deps); err != nil {
+		return nil, fmt.Errorf("post: %w", err)
+	}
+	if p.feedback, err = processor.NewFromSteps(config.Pipeline.Feedback, h.deps); err != nil {
+		return nil, fmt.Errorf("feedback: %w", err)
+	}
+	return p, nil
+}
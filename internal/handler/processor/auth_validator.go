@@ -1,6 +1,7 @@
 package processor
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,11 +31,15 @@ func NewAuthValidatorProcessor(githubController *internalGitHub.Controller, opts
 	return _inst
 }
 
+func (p *authValidatorProcessor) Name() string {
+	return "auth-validator"
+}
+
 func (p *authValidatorProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("pre-processor:validator")
 }
 
-func (p *authValidatorProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *authValidatorProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	authRequest, ok := req.(*AuthRequest)
 	if !ok {
 		return nil, promotion.NewInternalErrorf("invalid event type. expected *AuthRequest got %T", req)
@@ -55,7 +60,7 @@ func (p *authValidatorProcessor) Process(req any) (bus *promotion.Bus, err error
 		p.logger.Error("missing event type")
 		return &promotion.Bus{
 			Response: models.Response{Body: "missing event type", StatusCode: http.StatusUnprocessableEntity},
-		}, promotion.NewInternalError("missing event type")
+		}, promotion.NewErrorf(promotion.KindInvalidInput, "missing event type")
 	}
 	bus.EventType = event.Type(eventType)
 
@@ -64,7 +69,7 @@ func (p *authValidatorProcessor) Process(req any) (bus *promotion.Bus, err error
 		p.logger.Error("missing delivery ID")
 		return &promotion.Bus{
 			Response: models.Response{Body: "missing delivery ID", StatusCode: http.StatusUnprocessableEntity},
-		}, promotion.NewInternalError("missing delivery ID")
+		}, promotion.NewErrorf(promotion.KindInvalidInput, "missing delivery ID")
 	}
 
 	// Validate the request
@@ -73,50 +78,63 @@ func (p *authValidatorProcessor) Process(req any) (bus *promotion.Bus, err error
 		p.logger.Error("failed to validate request", slog.Any("error", err))
 		return &promotion.Bus{
 			Response: *resp,
-		}, promotion.NewInternalErrorf("failed to validate request. error: %v", err)
+		}, promotion.WithStatus(promotion.NewErrorf(promotion.KindInvalidInput, "failed to validate request. error: %v", err), resp.StatusCode)
 	}
 
 	// Add the event type to the logger now that we know it's valid
 	p.logger = p.logger.With(slog.String("event", eventType))
 
 	// Refresh credentials if needed
-	if err = p.githubController.RetrieveCredentials(); err != nil {
+	if err = p.githubController.RetrieveCredentials(ctx); err != nil {
 		p.logger.Error("failed to refresh credentials", slog.Any("error", err))
+		err = fmt.Errorf("failed to refresh credentials. error: %w", err)
 		return &promotion.Bus{
-			Response: models.Response{Body: err.Error(), StatusCode: http.StatusUnauthorized},
-		}, promotion.NewInternalErrorf("failed to refresh credentials. error: %v", err)
+			Response: models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)},
+		}, err
 	}
-	if config.Global.Mode != config.ModeLambdaEvent {
+	switch {
+	case authRequest.SkipSignatureValidation:
+		p.logger.Debug("skipping webhook signature validation...")
+	case config.Global.Mode == config.ModeLambdaEvent:
+		p.logger.Debug("skipping webhook signature validation in lambda-event mode...")
+	default:
 		if err = p.githubController.ValidateWebhookSecret(body, headers); err != nil {
 			p.logger.Error("failed to validate signature", slog.Any("error", err))
 			return &promotion.Bus{
 				Response: models.Response{Body: err.Error(), StatusCode: http.StatusForbidden},
-			}, promotion.NewInternalErrorf("failed to validate signature. error: %v", err)
+			}, promotion.WithStatus(promotion.NewErrorf(promotion.KindAuth, "failed to validate signature. error: %v", err), http.StatusForbidden)
 		}
 		p.logger.Debug("request body is valid")
-	} else {
-		p.logger.Debug("skipping webhook signature validation in lambda-event mode...")
 	}
 
 	// Add the delivery ID to the logger, now that we know the payload is valid
 	p.logger = p.logger.With(slog.String("deliveryID", deliveryID))
+	bus.DeliveryID = deliveryID
 
 	repo, err := p.extractRepositoryContext(body)
 	if err != nil {
 		p.logger.Error("failed to extract repository context", slog.Any("error", err))
 		return &promotion.Bus{
 			Response: models.Response{Body: err.Error(), StatusCode: http.StatusUnprocessableEntity},
-		}, promotion.NewInternalErrorf("failed to extract repository context. error: %v", err)
+		}, promotion.NewErrorf(promotion.KindInvalidInput, "failed to extract repository context. error: %v", err)
 	}
 
 	p.logger = p.logger.With(slog.Any("repo", repo.FullName))
 	p.logger.Debug("authenticating...")
-	var clients *internalGitHub.Client
-	if clients, err = p.githubController.GetGitHubClients(body); err != nil {
+	clients := new(internalGitHub.Client)
+	if event.IsInstallationEvent(bus.EventType) {
+		// Installation events may concern suspended or deleted installations: their processors spawn the clients they need
+		p.logger.Debug("skipping authentication of installation event...")
+	} else if clients, err = p.githubController.GetGitHubClients(ctx, body); err != nil {
 		p.logger.Error("failed to authenticate", slog.Any("error", err))
+		if promotion.Classify(err) == promotion.KindInternal {
+			// Failures to spawn the installation clients are authentication failures unless known to be transient
+			err = promotion.NewError(promotion.KindAuth, err)
+		}
+		err = fmt.Errorf("failed to authenticate. error: %w", err)
 		return &promotion.Bus{
-			Response: models.Response{Body: err.Error(), StatusCode: http.StatusUnauthorized},
-		}, promotion.NewInternalErrorf("failed to authenticate. error: %v", err)
+			Response: models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)},
+		}, err
 	}
 
 	evt, err := github.ParseWebHook(eventType, body)
@@ -124,15 +142,20 @@ func (p *authValidatorProcessor) Process(req any) (bus *promotion.Bus, err error
 		p.logger.Error("failed to parse webhook payload", slog.Any("error", err))
 		return &promotion.Bus{
 			Response: models.Response{Body: err.Error(), StatusCode: http.StatusUnprocessableEntity},
-		}, promotion.NewInternalErrorf("failed to parse webhook payload. error: %v", err)
+		}, promotion.NewErrorf(promotion.KindInvalidInput, "failed to parse webhook payload. error: %v", err)
 	}
 
 	bus.Event = evt
 	bus.EventStatus = promotion.Error
 	bus.Repository = repo
+	// Installation events carry no repository
+	var owner *string
+	if repo.Owner != nil {
+		owner = repo.Owner.Login
+	}
 	bus.Context = &promotion.Context{
 		EventType:  evt,
-		Owner:      repo.Owner.Login,
+		Owner:      owner,
 		Repository: repo.Name,
 		Logger:     p.logger.WithGroup("runtime:promotion"),
 		ClientV3:   clients.V3,
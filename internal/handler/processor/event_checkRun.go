@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/google/go-github/v88/github"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type checkRunEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewCheckRunEventProcessor initializes a Processor for handling check run events with optional configurations.
+// Successful check runs trigger promotions like check suites do, while re-runs and "Retry promotion" requests
+// on the promotion check run re-evaluate the promotion and retry the fast-forward.
+func NewCheckRunEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &checkRunEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *checkRunEventProcessor) Name() string {
+	return "check-run-event"
+}
+
+func (p *checkRunEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:check-run")
+}
+
+func (p *checkRunEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing check-run event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.CheckRun) {
+		p.logger.Debug("check_run event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "check_run event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.CheckRunEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.CheckRunEvent got %T", evt)
+	}
+
+	if e.CheckRun == nil || e.CheckRun.HeadSHA == nil {
+		p.logger.Info("ignoring check run event without check run...")
+		bus.Skip(promotion.SkipUnprocessableState, "check run is missing its head SHA")
+		return bus, nil
+	}
+	checkRun := e.CheckRun
+	ours := checkRun.GetExternalID() == internalGitHub.PromotionCheckRunExternalID
+
+	switch action := e.GetAction(); action {
+	case "completed":
+		if ours {
+			// Our own feedback must not trigger another promotion
+			p.logger.Debug("ignoring completed promotion check run...")
+			bus.Skip(promotion.SkipUnprocessableState, "check run reports promotion feedback")
+			return bus, nil
+		}
+		if checkRun.GetStatus() != "completed" || checkRun.GetConclusion() != "success" {
+			p.logger.Info("ignoring non-success check run...",
+				slog.String("conclusion", checkRun.GetConclusion()),
+				slog.String("status", checkRun.GetStatus()))
+			bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("check run status %q, conclusion %q", checkRun.GetStatus(), checkRun.GetConclusion()))
+			return bus, nil
+		}
+	case "rerequested", "requested_action":
+		if !ours {
+			p.logger.Debug("ignoring request on a foreign check run...", slog.String("action", action))
+			bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("%s on check run %q not created by the promotion app", action, checkRun.GetName()))
+			return bus, nil
+		}
+		var identifier string
+		if requested := e.GetRequestedAction(); requested != nil {
+			identifier = requested.Identifier
+		}
+		if action == "requested_action" && identifier != internalGitHub.RetryPromotionAction {
+			p.logger.Info("ignoring unsupported requested action...", slog.String("identifier", identifier))
+			bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("requested action %q", identifier))
+			return bus, nil
+		}
+		p.logger.Info("retrying promotion on request...", slog.String("action", action), slog.String("sender", e.GetSender().GetLogin()))
+	default:
+		p.logger.Debug("ignoring check run event action...", slog.String("action", action))
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("check run action %q", action))
+		return bus, nil
+	}
+
+	bus.Context.HeadSHA = checkRun.HeadSHA
+
+	// Head SHAs fanning out of a stage are promoted into each target stage, all but the first with a fork of the bus
+	forkPromotionRequests(bus, slices.DeleteFunc(slices.Clone(checkRun.PullRequests), func(pr *github.PullRequest) bool {
+		return pr.GetHead().GetSHA() != *bus.Context.HeadSHA || !bus.Context.Promoter.IsPromotionRequest(pr)
+	}))
+
+	if bus.Context.BaseRef == nil || bus.Context.HeadRef == nil {
+		// The fast-forwarder looks the promotion request up by head SHA
+		p.logger.Info("check run event without matching promotion request...")
+		return bus, nil
+	}
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
+	return bus, nil
+}
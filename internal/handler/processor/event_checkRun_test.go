@@ -0,0 +1,147 @@
+package processor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRunEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Action             string
+		ExternalID         string
+		Conclusion         string
+		RequestedAction    string
+		ExpectedSkipReason promotion.SkipReason
+	}{
+		{
+			Name:       "completed_success",
+			Action:     "completed",
+			Conclusion: "success",
+		},
+		{
+			Name:               "completed_failure",
+			Action:             "completed",
+			Conclusion:         "failure",
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+		{
+			Name:               "completed_promotion_feedback",
+			Action:             "completed",
+			ExternalID:         internalGitHub.PromotionCheckRunExternalID,
+			Conclusion:         "success",
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+		{
+			Name:       "rerequested_promotion_check_run",
+			Action:     "rerequested",
+			ExternalID: internalGitHub.PromotionCheckRunExternalID,
+			Conclusion: "failure",
+		},
+		{
+			Name:               "rerequested_foreign_check_run",
+			Action:             "rerequested",
+			Conclusion:         "failure",
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+		{
+			Name:            "requested_retry",
+			Action:          "requested_action",
+			ExternalID:      internalGitHub.PromotionCheckRunExternalID,
+			Conclusion:      "failure",
+			RequestedAction: internalGitHub.RetryPromotionAction,
+		},
+		{
+			Name:               "requested_unsupported_action",
+			Action:             "requested_action",
+			ExternalID:         internalGitHub.PromotionCheckRunExternalID,
+			Conclusion:         "failure",
+			RequestedAction:    "deploy",
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+		{
+			Name:               "requested_action_on_foreign_check_run",
+			Action:             "requested_action",
+			Conclusion:         "failure",
+			RequestedAction:    internalGitHub.RetryPromotionAction,
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+		{
+			Name:               "created",
+			Action:             "created",
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			evt := &github.CheckRunEvent{
+				Action: &tc.Action,
+				CheckRun: &github.CheckRun{
+					Name:         new("build"),
+					HeadSHA:      new("c0ffee"),
+					ExternalID:   &tc.ExternalID,
+					Status:       new("completed"),
+					Conclusion:   &tc.Conclusion,
+					PullRequests: []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")},
+				},
+			}
+			if tc.RequestedAction != "" {
+				evt.RequestedAction = &github.RequestedAction{Identifier: tc.RequestedAction}
+			}
+			bus := newFakeGitHub(t).bus(t, event.CheckRun, evt, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewCheckRunEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			if tc.ExpectedSkipReason != "" {
+				assert.Equal(t, promotion.Skipped, bus.EventStatus)
+				return
+			}
+			// The promotion request of the check run is promoted
+			assert.Equal(t, "c0ffee", helpers.String(bus.Context.HeadSHA))
+			assert.Equal(t, "main", helpers.String(bus.Context.HeadRef))
+			assert.Equal(t, "staging", helpers.String(bus.Context.BaseRef))
+			assert.Equal(t, 1, bus.Context.PullRequest.GetNumber())
+		})
+	}
+}
+
+func TestCheckRunEventProcessorFansOut(t *testing.T) {
+	evt := &github.CheckRunEvent{
+		Action: new("completed"),
+		CheckRun: &github.CheckRun{
+			HeadSHA:    new("c0ffee"),
+			Status:     new("completed"),
+			Conclusion: new("success"),
+			PullRequests: []*github.PullRequest{
+				pullRequest(1, "staging", "canary-eu", "c0ffee"),
+				pullRequest(2, "staging", "canary-us", "c0ffee"),
+				// Stale promotion requests and foreign pull requests are left alone
+				pullRequest(3, "main", "staging", "deadbeef"),
+				pullRequest(4, "feature", "staging", "c0ffee"),
+			},
+		},
+	}
+	bus := newFakeGitHub(t).bus(t, event.CheckRun, evt)
+	promoter, err := promotion.NewGraphPromoter("test", "main > staging > canary-eu, canary-us")
+	require.NoError(t, err)
+	bus.Context.Promoter = promoter
+
+	bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewCheckRunEventProcessor(newController(t)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, bus.Context.PullRequest.GetNumber())
+	assert.Equal(t, "canary-eu", helpers.String(bus.Context.BaseRef))
+	require.Len(t, bus.Forks, 1)
+	assert.Equal(t, 2, bus.Forks[0].Context.PullRequest.GetNumber())
+	assert.Equal(t, "canary-us", helpers.String(bus.Forks[0].Context.BaseRef))
+}
@@ -1,7 +1,10 @@
 package processor
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/google/go-github/v88/github"
 	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
@@ -22,11 +25,15 @@ func NewCheckSuiteEventProcessor(githubController *internalGitHub.Controller, op
 	return _inst
 }
 
+func (p *checkSuiteEventProcessor) Name() string {
+	return "check-suite"
+}
+
 func (p *checkSuiteEventProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:check-suite")
 }
 
-func (p *checkSuiteEventProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *checkSuiteEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing check-suite event...")
 
 	if p.githubController == nil {
@@ -41,7 +48,7 @@ func (p *checkSuiteEventProcessor) Process(req any) (bus *promotion.Bus, err err
 
 	if !event.IsEnabled(event.CheckSuite) {
 		p.logger.Debug("check_suite event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "check_suite event is not enabled")
 		return bus, nil
 	}
 
@@ -55,7 +62,7 @@ func (p *checkSuiteEventProcessor) Process(req any) (bus *promotion.Bus, err err
 
 	if e.CheckSuite == nil || e.CheckSuite.Status == nil || e.CheckSuite.Conclusion == nil {
 		p.logger.Info("ignoring check suite event without check suite...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, "check suite is missing its status or conclusion")
 		return bus, nil
 	}
 
@@ -63,25 +70,25 @@ func (p *checkSuiteEventProcessor) Process(req any) (bus *promotion.Bus, err err
 		p.logger.Info("ignoring incomplete check suite event and/or non-success check-suite status...",
 			slog.String("conclusion", *e.CheckSuite.Conclusion),
 			slog.String("status", *e.CheckSuite.Status))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("check suite status %q, conclusion %q", *e.CheckSuite.Status, *e.CheckSuite.Conclusion))
 		return bus, nil
 	}
 
 	bus.Context.HeadSHA = e.CheckSuite.HeadSHA
 
-	for _, pr := range e.CheckSuite.PullRequests {
-		if *pr.Head.SHA == *bus.Context.HeadSHA && bus.Context.Promoter.IsPromotionRequest(pr) {
-			bus.Context.BaseRef = helpers.NormaliseRefPtr(*pr.Base.Ref)
-			bus.Context.HeadRef = helpers.NormaliseRefPtr(*pr.Head.Ref)
-			bus.Context.HeadSHA = pr.Head.SHA
-			bus.Context.PullRequest = pr
-			break
-		}
-	}
+	// Head SHAs fanning out of a stage are promoted into each target stage, all but the first with a fork of the bus
+	forkPromotionRequests(bus, slices.DeleteFunc(slices.Clone(e.CheckSuite.PullRequests), func(pr *github.PullRequest) bool {
+		return *pr.Head.SHA != *bus.Context.HeadSHA || !bus.Context.Promoter.IsPromotionRequest(pr)
+	}))
 
 	if bus.Context.BaseRef == nil || bus.Context.HeadRef == nil {
 		p.logger.Info("ignoring check suite event without matching promotion request...")
 		return bus, nil
 	}
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
 	return bus, nil
 }
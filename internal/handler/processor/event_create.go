@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type createEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewCreateEventProcessor initializes a Processor for handling ref creation events with optional configurations.
+// Creating a stage branch bootstraps the promotion request from the previous stage.
+func NewCreateEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &createEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *createEventProcessor) Name() string {
+	return "create"
+}
+
+func (p *createEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:create")
+}
+
+func (p *createEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing create event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.Create) {
+		p.logger.Debug("create event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "create event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.CreateEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.CreateEvent got %T", evt)
+	}
+
+	if e.GetRefType() != "branch" {
+		p.logger.Debug("ignoring creation of a non-branch ref...", slog.String("refType", e.GetRefType()))
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("created ref is a %s", e.GetRefType()))
+		return bus, nil
+	}
+
+	ref := e.GetRef()
+	previousStages := bus.Context.Promoter.PreviousStages(ref)
+	if len(previousStages) == 0 {
+		// The first stages have no previous stage to promote from
+		p.logger.Info("ignoring creation of a branch that is not promoted into", slog.String("ref", ref))
+		bus.Skip(promotion.SkipNotPromotionBranch, fmt.Sprintf("%s is not a promotion target", ref))
+		return bus, nil
+	}
+
+	// Join stages are promoted into from each previous stage: the promotion requests are opened one lock at a time, and
+	// the first one ahead of the created stage is carried by the bus, the others by forks of the bus
+	var promoted *promotion.Bus
+	for _, previousStage := range previousStages {
+		candidate := bus
+		if promoted != nil {
+			candidate = bus.Fork()
+		}
+		candidate.Context.HeadRef = helpers.NormaliseFullRefPtr(previousStage)
+		candidate.Context.BaseRef = helpers.NormaliseFullRefPtr(ref)
+		if err = candidate.LockBaseRef(ctx); err != nil {
+			return bus, err
+		}
+		opened, err := openStagePromotionRequest(ctx, p.githubController, p.logger, candidate)
+		candidate.Unlock()
+		if err != nil {
+			bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
+			return bus, err
+		}
+		if !opened {
+			continue
+		}
+		// send feedback commit status: pending
+		candidate.EventStatus = promotion.Pending
+		if promoted == nil {
+			promoted = candidate
+		} else {
+			bus.Forks = append(bus.Forks, candidate)
+		}
+	}
+	if promoted == nil {
+		bus.Skip(promotion.SkipAlreadyPromoted, fmt.Sprintf("%s is not ahead of the created %s", strings.Join(previousStages, ", "), ref))
+		return bus, nil
+	}
+
+	// Serialise the remaining processing of events targeting the same base ref
+	return bus, bus.LockBaseRef(ctx)
+}
@@ -0,0 +1,120 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Ref                string
+		RefType            string
+		Setup              func(fake *fakeGitHub)
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedCreated    []string
+	}{
+		{
+			Name:    "stage_behind_previous_stage",
+			Ref:     "staging",
+			RefType: "branch",
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "beef")
+				fake.onCompare("staging", "main", "ahead", 1)
+			},
+			ExpectedCreated: []string{`"head":"refs/heads/main","base":"refs/heads/staging"`},
+		},
+		{
+			Name:    "stage_level_with_previous_stage",
+			Ref:     "staging",
+			RefType: "branch",
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "c0ffee")
+				fake.onCompare("staging", "main", "identical", 0)
+			},
+			ExpectedSkipReason: promotion.SkipAlreadyPromoted,
+		},
+		{
+			Name:               "first_stage",
+			Ref:                "main",
+			RefType:            "branch",
+			Setup:              func(*fakeGitHub) {},
+			ExpectedSkipReason: promotion.SkipNotPromotionBranch,
+		},
+		{
+			Name:               "non_promotion_branch",
+			Ref:                "feature",
+			RefType:            "branch",
+			Setup:              func(*fakeGitHub) {},
+			ExpectedSkipReason: promotion.SkipNotPromotionBranch,
+		},
+		{
+			Name:               "tag",
+			Ref:                "staging",
+			RefType:            "tag",
+			Setup:              func(*fakeGitHub) {},
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+			fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(1, "main", "staging", "c0ffee"))
+			tc.Setup(fake)
+			bus := fake.bus(t, event.Create, &github.CreateEvent{Ref: &tc.Ref, RefType: &tc.RefType}, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewCreateEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			created := fake.received(http.MethodPost, "/repos/owner/repo/pulls")
+			require.Len(t, created, len(tc.ExpectedCreated))
+			for i, expected := range tc.ExpectedCreated {
+				assert.Contains(t, created[i].Body, expected)
+			}
+			if len(tc.ExpectedCreated) > 0 {
+				assert.Equal(t, promotion.Pending, bus.EventStatus)
+				assert.Equal(t, 1, bus.Context.PullRequest.GetNumber())
+			}
+		})
+	}
+}
+
+func TestCreateEventProcessorJoinStage(t *testing.T) {
+	fake := newFakeGitHub(t)
+	fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+	fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(1, "canary-eu", "production", "c0ffee"))
+	fake.onRef("canary-eu", "c0ffee")
+	fake.onRef("canary-us", "c0ffee")
+	fake.onRef("production", "beef")
+	fake.onCompare("production", "canary-eu", "ahead", 1)
+	fake.onCompare("production", "canary-us", "ahead", 1)
+	bus := fake.bus(t, event.Create, &github.CreateEvent{Ref: new("production"), RefType: new("branch")})
+	promoter, err := promotion.NewGraphPromoter("test", "main > canary-eu, canary-us > production")
+	require.NoError(t, err)
+	bus.Context.Promoter = promoter
+
+	bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewCreateEventProcessor(newController(t)))
+	require.NoError(t, err)
+	// Each previous stage of the join stage is promoted into it, all but the first by a fork of the bus
+	created := fake.received(http.MethodPost, "/repos/owner/repo/pulls")
+	require.Len(t, created, 2)
+	assert.Contains(t, created[0].Body, `"head":"refs/heads/canary-eu","base":"refs/heads/production"`)
+	assert.Contains(t, created[1].Body, `"head":"refs/heads/canary-us","base":"refs/heads/production"`)
+	assert.Equal(t, promotion.Pending, bus.EventStatus)
+	require.Len(t, bus.Forks, 1)
+	assert.Equal(t, promotion.Pending, bus.Forks[0].EventStatus)
+	assert.Equal(t, "refs/heads/canary-us", helpers.String(bus.Forks[0].Context.HeadRef))
+}
@@ -0,0 +1,138 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type customPropertyValuesEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewCustomPropertyValuesEventProcessor initializes a Processor for handling repository custom property values events.
+// It reconciles the open promotion requests of the repository with the promotion path defined by its custom properties.
+func NewCustomPropertyValuesEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &customPropertyValuesEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *customPropertyValuesEventProcessor) Name() string {
+	return "custom-property-values"
+}
+
+func (p *customPropertyValuesEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:custom-property-values")
+}
+
+func (p *customPropertyValuesEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing custom-property-values event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.CustomPropertyValues) {
+		p.logger.Debug("custom_property_values event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "custom_property_values event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.CustomPropertyValuesEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.CustomPropertyValuesEvent got %T", evt)
+	}
+
+	if !config.Promotion.Dynamic.Enabled {
+		p.logger.Debug("dynamic promotion is disabled. skipping...")
+		bus.Skip(promotion.SkipUnprocessableState, "dynamic promotion is disabled")
+		return bus, nil
+	}
+	key := config.Promotion.Dynamic.Key
+	if !slices.ContainsFunc(e.NewPropertyValues, func(v *github.CustomPropertyValue) bool { return v.PropertyName == key }) &&
+		!slices.ContainsFunc(e.OldPropertyValues, func(v *github.CustomPropertyValue) bool { return v.PropertyName == key }) {
+		p.logger.Debug("ignoring change of unrelated custom properties...")
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("custom property %s is unchanged", key))
+		return bus, nil
+	}
+
+	oldPromoter := promotion.NewDynamicPromoter(p.logger, withPropertyValues(bus.Repository.CustomProperties, e.OldPropertyValues), key, config.Promotion.Dynamic.Class)
+	newPromoter := promotion.NewDynamicPromoter(p.logger, withPropertyValues(bus.Repository.CustomProperties, e.NewPropertyValues), key, config.Promotion.Dynamic.Class)
+	bus.Context.Promoter = newPromoter
+	logger := p.logger.With(slog.String("oldPath", oldPromoter.String()), slog.String("newPath", newPromoter.String()))
+	logger.Info("reconciling promotion requests with the new promotion path...")
+
+	// Close the promotion requests of stage pairs that were removed from the promotion path
+	orphans, err := p.githubController.ListPromotionRequests(ctx, bus.Context, oldPromoter)
+	if err != nil {
+		logger.Error("failed to list promotion requests", slog.Any("error", err))
+		return bus, err
+	}
+	var closed, opened int
+	for _, pr := range orphans {
+		if newPromoter.IsPromotionRequest(pr) {
+			continue
+		}
+		reason := fmt.Sprintf("`%s` → `%s` is no longer part of the promotion path `%s` defined by the `%s` custom property",
+			*pr.Head.Ref, *pr.Base.Ref, newPromoter, key)
+		if err = closePromotionRequest(ctx, p.githubController, logger, bus, pr, reason); err != nil {
+			return bus, err
+		}
+		closed++
+	}
+
+	// Open the promotion requests of stage pairs that were added to the promotion path
+	for _, pair := range newPromoter.Pairs() {
+		if slices.Contains(oldPromoter.NextStages(pair.Source), pair.Target) {
+			continue
+		}
+		ok, err := openStagePair(ctx, p.githubController, p.logger, bus, pair.Source, pair.Target)
+		if err != nil {
+			logger.Error("failed to reconcile stage pair", slog.String("source", pair.Source), slog.String("target", pair.Target), slog.Any("error", err))
+			return bus, err
+		}
+		if ok {
+			opened++
+		}
+	}
+
+	msg := fmt.Sprintf("Promotion requests reconciled: %d closed, %d opened", closed, opened)
+	bus.Response = models.Response{Body: msg, StatusCode: http.StatusOK}
+	bus.Skip(promotion.SkipRepositoryEvent, msg)
+	return bus, nil
+}
+
+// withPropertyValues returns a copy of the custom properties updated with the given values. Properties set to null are removed.
+func withPropertyValues(props map[string]any, values []*github.CustomPropertyValue) map[string]any {
+	updated := maps.Clone(props)
+	if updated == nil {
+		updated = make(map[string]any, len(values))
+	}
+	for _, value := range values {
+		if value.Value == nil {
+			delete(updated, value.PropertyName)
+			continue
+		}
+		updated[value.PropertyName] = value.Value
+	}
+	return updated
+}
@@ -0,0 +1,133 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// promotionPath returns the change of the promotion path custom property to the given value.
+func promotionPath(value any) []*github.CustomPropertyValue {
+	return []*github.CustomPropertyValue{{PropertyName: config.Promotion.Dynamic.Key, Value: value}}
+}
+
+func TestCustomPropertyValuesEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Disabled           bool
+		OldValues          []*github.CustomPropertyValue
+		NewValues          []*github.CustomPropertyValue
+		Setup              func(fake *fakeGitHub)
+		ExpectedClosed     []string
+		ExpectedOpened     []string
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedBody       string
+	}{
+		{
+			Name:      "stage_removed",
+			OldValues: promotionPath("main, staging, production"),
+			NewValues: promotionPath("main, production"),
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{
+					pullRequest(1, "main", "staging", "c0ffee"),
+					pullRequest(2, "staging", "production", "beef"),
+				})
+				fake.onRef("main", "c0ffee")
+				fake.onRef("production", "f00d")
+				fake.onCompare("production", "main", "ahead", 2)
+			},
+			ExpectedClosed:     []string{"/repos/owner/repo/pulls/1", "/repos/owner/repo/pulls/2"},
+			ExpectedOpened:     []string{`"head":"refs/heads/main","base":"refs/heads/production"`},
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedBody:       "Promotion requests reconciled: 2 closed, 1 opened",
+		},
+		{
+			Name:      "stage_added",
+			OldValues: promotionPath("main, production"),
+			NewValues: promotionPath("main, staging, production"),
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{
+					pullRequest(1, "main", "production", "c0ffee"),
+				})
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "beef")
+				fake.onRef("production", "beef")
+				fake.onCompare("staging", "main", "ahead", 1)
+				fake.onCompare("production", "staging", "identical", 0)
+			},
+			ExpectedClosed:     []string{"/repos/owner/repo/pulls/1"},
+			ExpectedOpened:     []string{`"head":"refs/heads/main","base":"refs/heads/staging"`},
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedBody:       "Promotion requests reconciled: 1 closed, 1 opened",
+		},
+		{
+			Name:      "unchanged_promotion_requests",
+			OldValues: promotionPath("main, staging, production"),
+			NewValues: promotionPath("main, staging, production"),
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{
+					pullRequest(1, "main", "staging", "c0ffee"),
+				})
+			},
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedBody:       "Promotion requests reconciled: 0 closed, 0 opened",
+		},
+		{
+			Name:               "unrelated_property",
+			OldValues:          []*github.CustomPropertyValue{{PropertyName: "team", Value: "a"}},
+			NewValues:          []*github.CustomPropertyValue{{PropertyName: "team", Value: "b"}},
+			Setup:              func(*fakeGitHub) {},
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+		{
+			Name:               "dynamic_promotion_disabled",
+			Disabled:           true,
+			OldValues:          promotionPath("main, staging, production"),
+			NewValues:          promotionPath("main, production"),
+			Setup:              func(*fakeGitHub) {},
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			restoreConfig(t)
+			config.Promotion.Dynamic.Enabled = !tc.Disabled
+			fake := newFakeGitHub(t)
+			tc.Setup(fake)
+			fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(3, "main", "staging", "c0ffee"))
+			fake.on(http.MethodPatch, "/repos/owner/repo/pulls/1", http.StatusOK, github.PullRequest{})
+			fake.on(http.MethodPatch, "/repos/owner/repo/pulls/2", http.StatusOK, github.PullRequest{})
+			bus := fake.bus(t, event.CustomPropertyValues, &github.CustomPropertyValuesEvent{
+				OldPropertyValues: tc.OldValues,
+				NewPropertyValues: tc.NewValues,
+			})
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewCustomPropertyValuesEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.Equal(t, tc.ExpectedBody, bus.Response.Body)
+
+			var closed []string
+			for _, req := range fake.receivedPrefix(http.MethodPatch, "/repos/owner/repo/pulls/") {
+				assert.Contains(t, req.Body, `"state":"closed"`)
+				closed = append(closed, req.Path)
+			}
+			assert.Equal(t, tc.ExpectedClosed, closed)
+			opened := fake.received(http.MethodPost, "/repos/owner/repo/pulls")
+			require.Len(t, opened, len(tc.ExpectedOpened))
+			for i, expected := range tc.ExpectedOpened {
+				assert.Contains(t, opened[i].Body, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/go-github/v88/github"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type deleteEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewDeleteEventProcessor initializes a Processor for handling ref deletion events with optional configurations.
+// Deleting a stage branch closes the open promotion requests from and into it.
+func NewDeleteEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &deleteEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *deleteEventProcessor) Name() string {
+	return "delete"
+}
+
+func (p *deleteEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:delete")
+}
+
+func (p *deleteEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing delete event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.Delete) {
+		p.logger.Debug("delete event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "delete event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.DeleteEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.DeleteEvent got %T", evt)
+	}
+
+	if e.GetRefType() != "branch" {
+		p.logger.Debug("ignoring deletion of a non-branch ref...", slog.String("refType", e.GetRefType()))
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("deleted ref is a %s", e.GetRefType()))
+		return bus, nil
+	}
+
+	ref := e.GetRef()
+	if bus.Context.Promoter.StageIndex(ref) == -1 {
+		p.logger.Info("ignoring deletion of non-promotion branch", slog.String("ref", ref))
+		bus.Skip(promotion.SkipNotPromotionBranch, fmt.Sprintf("%s is not a promotion stage", ref))
+		return bus, nil
+	}
+
+	prs, err := p.githubController.ListPromotionRequests(ctx, bus.Context, bus.Context.Promoter)
+	if err != nil {
+		p.logger.Error("failed to list promotion requests", slog.Any("error", err))
+		return bus, err
+	}
+	var closed int
+	for _, pr := range prs {
+		if helpers.NormaliseRef(*pr.Head.Ref) != ref && helpers.NormaliseRef(*pr.Base.Ref) != ref {
+			continue
+		}
+		if err = closePromotionRequest(ctx, p.githubController, p.logger, bus, pr, fmt.Sprintf("stage branch `%s` was deleted", ref)); err != nil {
+			return bus, err
+		}
+		closed++
+	}
+
+	msg := fmt.Sprintf("Stage branch %s deleted: %d promotion requests closed", ref, closed)
+	bus.Response = models.Response{Body: msg, StatusCode: http.StatusOK}
+	bus.Skip(promotion.SkipRepositoryEvent, msg)
+	return bus, nil
+}
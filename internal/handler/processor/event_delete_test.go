@@ -0,0 +1,81 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Ref                string
+		RefType            string
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedClosed     []string
+		ExpectedBody       string
+	}{
+		{
+			Name:               "stage",
+			Ref:                "staging",
+			RefType:            "branch",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedClosed:     []string{"/repos/owner/repo/pulls/1", "/repos/owner/repo/pulls/2"},
+			ExpectedBody:       "Stage branch staging deleted: 2 promotion requests closed",
+		},
+		{
+			Name:               "last_stage",
+			Ref:                "production",
+			RefType:            "branch",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedClosed:     []string{"/repos/owner/repo/pulls/2"},
+			ExpectedBody:       "Stage branch production deleted: 1 promotion requests closed",
+		},
+		{
+			Name:               "non_promotion_branch",
+			Ref:                "feature",
+			RefType:            "branch",
+			ExpectedSkipReason: promotion.SkipNotPromotionBranch,
+		},
+		{
+			Name:               "tag",
+			Ref:                "staging",
+			RefType:            "tag",
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{
+				pullRequest(1, "main", "staging", "c0ffee"),
+				pullRequest(2, "staging", "production", "beef"),
+				pullRequest(3, "feature", "staging", "f00d"),
+			})
+			for _, number := range []string{"1", "2", "3"} {
+				fake.on(http.MethodPatch, "/repos/owner/repo/pulls/"+number, http.StatusOK, github.PullRequest{})
+			}
+			bus := fake.bus(t, event.Delete, &github.DeleteEvent{Ref: &tc.Ref, RefType: &tc.RefType}, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewDeleteEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.Equal(t, tc.ExpectedBody, bus.Response.Body)
+			var closed []string
+			for _, req := range fake.receivedPrefix(http.MethodPatch, "/repos/owner/repo/pulls/") {
+				assert.Contains(t, req.Body, `"state":"closed"`)
+				closed = append(closed, req.Path)
+			}
+			assert.Equal(t, tc.ExpectedClosed, closed)
+		})
+	}
+}
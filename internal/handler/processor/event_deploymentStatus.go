@@ -1,9 +1,15 @@
 package processor
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
 	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
@@ -22,11 +28,15 @@ func NewDeploymentStatusEventProcessor(githubController *internalGitHub.Controll
 	return _inst
 }
 
+func (p *deploymentStatusProcessor) Name() string {
+	return "deployment-status"
+}
+
 func (p *deploymentStatusProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:deployment-status")
 }
 
-func (p *deploymentStatusProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *deploymentStatusProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing deployment-status event...")
 
 	if p.githubController == nil {
@@ -41,7 +51,7 @@ func (p *deploymentStatusProcessor) Process(req any) (bus *promotion.Bus, err er
 
 	if !event.IsEnabled(event.DeploymentStatus) {
 		p.logger.Debug("deployment_status event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "deployment_status event is not enabled")
 		return bus, nil
 	}
 
@@ -53,12 +63,59 @@ func (p *deploymentStatusProcessor) Process(req any) (bus *promotion.Bus, err er
 	state := *e.DeploymentStatus.State
 	if state != "success" {
 		p.logger.Debug("ignoring non-success deployment status event with unprocessable deployment status state...", slog.String("state", state))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("deployment status state %q", state))
 		return bus, nil
 	}
 
 	bus.Context.HeadRef = helpers.NormaliseFullRefPtr(*e.Deployment.Ref)
 	bus.Context.HeadSHA = e.Deployment.SHA
 
+	nextStages, isPromotable := bus.Context.Promoter.IsPromotableRef(*bus.Context.HeadRef)
+	if !isPromotable {
+		p.logger.Info("ignoring deployment of non-promotion branch", slog.String("headRef", *bus.Context.HeadRef))
+		bus.Skip(promotion.SkipNotPromotionBranch, fmt.Sprintf("%s is not a promotion source", *bus.Context.HeadRef))
+		return bus, nil
+	}
+	environment := e.Deployment.GetEnvironment()
+	gated := slices.DeleteFunc(slices.Clone(nextStages), func(nextStage string) bool {
+		required := config.Promotion.Stage(nextStage).DeploymentEnvironment
+		return required != "" && environment != required
+	})
+	if len(gated) == 0 {
+		p.logger.Info("ignoring deployment to an environment not gating the promotion", slog.String("environment", environment), slog.Any("nextStages", nextStages))
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("deployment environment %q does not gate promotions into %s", environment, strings.Join(nextStages, ", ")))
+		return bus, nil
+	}
+
+	// Stages fanning out promote into each next stage the deployment gates, all but the first with a fork of the bus
+	var (
+		prs     []*github.PullRequest
+		findErr error
+	)
+	for _, nextStage := range gated {
+		pCtx := *bus.Context
+		pCtx.BaseRef = helpers.NormaliseFullRefPtr(nextStage)
+		pr, err := p.githubController.FindPullRequest(ctx, &pCtx)
+		if errors.Is(err, internalGitHub.ErrNoPromotionRequest) {
+			findErr = err
+			continue
+		}
+		if err != nil {
+			p.logger.Error("failed to find promotion PR", slog.Any("error", err))
+			return bus, err
+		}
+		prs = append(prs, pr)
+	}
+	if len(prs) == 0 {
+		p.logger.Info("ignoring deployment status event without matching promotion request...")
+		bus.Skip(promotion.SkipNoPromotionRequest, findErr.Error())
+		return bus, nil
+	}
+	forkPromotionRequests(bus, prs)
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
 	return bus, nil
 }
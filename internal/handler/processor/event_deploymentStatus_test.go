@@ -0,0 +1,109 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentStatusEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		Environment         string
+		State               string
+		Ref                 string
+		StagingEnvironment  string
+		PullRequests        []*github.PullRequest
+		ExpectedSkipReason  promotion.SkipReason
+		ExpectedFastForward bool
+	}{
+		{
+			Name:                "gating_environment",
+			Environment:         "dev",
+			State:               "success",
+			Ref:                 "main",
+			StagingEnvironment:  "dev",
+			PullRequests:        []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")},
+			ExpectedFastForward: true,
+		},
+		{
+			Name:                "ungated_stage",
+			Environment:         "preview",
+			State:               "success",
+			Ref:                 "main",
+			PullRequests:        []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")},
+			ExpectedFastForward: true,
+		},
+		{
+			Name:               "non_gating_environment",
+			Environment:        "preview",
+			State:              "success",
+			Ref:                "main",
+			StagingEnvironment: "dev",
+			PullRequests:       []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")},
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+		{
+			Name:               "failed_deployment",
+			Environment:        "dev",
+			State:              "failure",
+			Ref:                "main",
+			StagingEnvironment: "dev",
+			PullRequests:       []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")},
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+		{
+			Name:               "non_promotion_branch",
+			Environment:        "dev",
+			State:              "success",
+			Ref:                "feature",
+			StagingEnvironment: "dev",
+			ExpectedSkipReason: promotion.SkipNotPromotionBranch,
+		},
+		{
+			Name:               "no_promotion_request",
+			Environment:        "dev",
+			State:              "success",
+			Ref:                "main",
+			StagingEnvironment: "dev",
+			PullRequests:       []*github.PullRequest{},
+			ExpectedSkipReason: promotion.SkipNoPromotionRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.DeploymentEnvironment = tc.StagingEnvironment })
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, tc.PullRequests)
+			fake.on(http.MethodGet, "/repos/owner/repo/issues/1/labels", http.StatusOK, []*github.Label{})
+			fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+			bus := fake.bus(t, event.DeploymentStatus, &github.DeploymentStatusEvent{
+				Deployment:       &github.Deployment{Ref: &tc.Ref, SHA: new("c0ffee"), Environment: &tc.Environment},
+				DeploymentStatus: &github.DeploymentStatus{State: &tc.State},
+			}, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewDeploymentStatusEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			// Skipped events are not post-processed
+			if bus.EventStatus != promotion.Skipped {
+				bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+				require.NoError(t, err)
+				assert.Equal(t, promotion.Success, bus.EventStatus)
+			}
+			assert.Equal(t, tc.ExpectedFastForward, len(fake.received(http.MethodPatch, fastForwardPath)) > 0)
+			// Deployment status events are their own deployment gate
+			assert.Empty(t, fake.receivedPrefix(http.MethodGet, "/repos/owner/repo/deployments"))
+		})
+	}
+}
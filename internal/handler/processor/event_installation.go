@@ -0,0 +1,149 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type installationEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewInstallationEventProcessor initializes a Processor for handling app installation events with optional configurations.
+// It drops the cached clients of deleted and suspended installations, and pre-warms those of created and unsuspended ones.
+func NewInstallationEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &installationEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *installationEventProcessor) Name() string {
+	return "installation"
+}
+
+func (p *installationEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:installation")
+}
+
+func (p *installationEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing installation event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.Installation) {
+		p.logger.Debug("installation event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "installation event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.InstallationEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.InstallationEvent got %T", evt)
+	}
+
+	installationID := e.GetInstallation().GetID()
+	logger := p.logger.With(slog.Int64("installationId", installationID), slog.String("action", e.GetAction()))
+	var msg string
+	switch e.GetAction() {
+	case "deleted", "suspend":
+		p.githubController.InvalidateClients(installationID)
+		logger.Info("dropped installation clients")
+		msg = fmt.Sprintf("Installation %d %s: clients dropped", installationID, e.GetAction())
+	case "created", "unsuspend", "new_permissions_accepted":
+		clients, err := warmInstallation(ctx, p.githubController, installationID)
+		if err != nil {
+			logger.Error("failed to pre-warm installation clients", slog.Any("error", err))
+			return bus, err
+		}
+		logger.Info("pre-warmed installation clients")
+		msg = fmt.Sprintf("Installation %d %s: clients pre-warmed", installationID, e.GetAction())
+		if config.Promotion.Installation.Reconcile && len(e.Repositories) > 0 {
+			opened, err := reconcileRepositories(ctx, p.githubController, logger, bus, clients, e.Repositories)
+			if err != nil {
+				return bus, err
+			}
+			msg += fmt.Sprintf(", %d promotion requests opened", opened)
+		}
+	default:
+		logger.Debug("ignoring installation event action...")
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("installation action %q", e.GetAction()))
+		return bus, nil
+	}
+
+	bus.Response = models.Response{Body: msg, StatusCode: http.StatusOK}
+	bus.Skip(promotion.SkipRepositoryEvent, msg)
+	return bus, nil
+}
+
+// warmInstallation replaces the cached clients of the installation with freshly spawned ones, whose token covers
+// the current repositories and permissions of the installation.
+func warmInstallation(ctx context.Context, githubController *internalGitHub.Controller, installationID int64) (*internalGitHub.Client, error) {
+	githubController.InvalidateClients(installationID)
+	return githubController.GetInstallationClients(ctx, installationID)
+}
+
+// reconcileRepositories opens the promotion requests of every stage pair whose source is ahead of its target in each of
+// the given installation repositories. Failures do not stop the reconciliation of the other repositories.
+func reconcileRepositories(ctx context.Context, githubController *internalGitHub.Controller, logger *slog.Logger, bus *promotion.Bus, clients *internalGitHub.Client, repos []*github.Repository) (int, error) {
+	var (
+		opened int
+		errs   []error
+	)
+	for _, repo := range repos {
+		// Installation payloads only carry the repository names
+		owner, name, _ := strings.Cut(repo.GetFullName(), "/")
+		pCtx := &promotion.Context{
+			EventType:  bus.Context.EventType,
+			Owner:      &owner,
+			Repository: &name,
+			Logger:     bus.Context.Logger,
+			ClientV3:   clients.V3,
+			ClientV4:   clients.V4,
+		}
+		repoLogger := logger.With(slog.String("repository", repo.GetFullName()))
+
+		fetched, err := githubController.GetRepository(ctx, pCtx)
+		if err != nil {
+			repoLogger.Error("failed to get repository", slog.Any("error", err))
+			errs = append(errs, err)
+			continue
+		}
+		pCtx.Promoter = NewPromoter(repoLogger, fetched.CustomProperties)
+		repoBus := &promotion.Bus{
+			Context:    pCtx,
+			Repository: &models.RepositoryContext{Name: &name, FullName: repo.FullName, CustomProperties: fetched.CustomProperties},
+			Locker:     bus.Locker,
+		}
+
+		n, err := reconcileStagePairs(ctx, githubController, repoLogger, repoBus)
+		opened += n
+		if err != nil {
+			repoLogger.Error("failed to reconcile repository", slog.Any("error", err))
+			errs = append(errs, fmt.Errorf("%s: %w", repo.GetFullName(), err))
+			continue
+		}
+		repoLogger.Info("reconciled repository", slog.Int("opened", n))
+	}
+	return opened, errors.Join(errs...)
+}
@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type installationRepositoriesEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewInstallationRepositoriesEventProcessor initializes a Processor for handling repositories added to an app installation.
+// It pre-warms the installation clients and, if enabled, opens the promotion requests of the added repositories.
+func NewInstallationRepositoriesEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &installationRepositoriesEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *installationRepositoriesEventProcessor) Name() string {
+	return "installation-repositories"
+}
+
+func (p *installationRepositoriesEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:installation-repositories")
+}
+
+func (p *installationRepositoriesEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing installation-repositories event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.InstallationRepositories) {
+		p.logger.Debug("installation_repositories event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "installation_repositories event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.InstallationRepositoriesEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.InstallationRepositoriesEvent got %T", evt)
+	}
+
+	if e.GetAction() != "added" {
+		p.logger.Debug("ignoring installation repositories event action...", slog.String("action", e.GetAction()))
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("installation repositories action %q", e.GetAction()))
+		return bus, nil
+	}
+
+	installationID := e.GetInstallation().GetID()
+	logger := p.logger.With(slog.Int64("installationId", installationID))
+	// The token of the cached clients does not cover the added repositories
+	clients, err := warmInstallation(ctx, p.githubController, installationID)
+	if err != nil {
+		logger.Error("failed to pre-warm installation clients", slog.Any("error", err))
+		return bus, err
+	}
+	logger.Info("pre-warmed installation clients", slog.Int("added", len(e.RepositoriesAdded)))
+	msg := fmt.Sprintf("Installation %d: %d repositories added", installationID, len(e.RepositoriesAdded))
+
+	if config.Promotion.Installation.Reconcile {
+		opened, err := reconcileRepositories(ctx, p.githubController, logger, bus, clients, e.RepositoriesAdded)
+		if err != nil {
+			return bus, err
+		}
+		msg += fmt.Sprintf(", %d promotion requests opened", opened)
+	}
+
+	bus.Response = models.Response{Body: msg, StatusCode: http.StatusOK}
+	bus.Skip(promotion.SkipRepositoryEvent, msg)
+	return bus, nil
+}
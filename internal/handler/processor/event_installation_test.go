@@ -0,0 +1,179 @@
+package processor_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInstallationID = int64(42)
+
+// newCachingController returns a controller authenticated with a personal access token, caching the given stale
+// clients of the test installation.
+func newCachingController(t *testing.T, stale *internalGitHub.Client) (*internalGitHub.Controller, *internalGitHub.ClientCache) {
+	t.Helper()
+	cache := internalGitHub.NewClientCache()
+	cache.Put(testInstallationID, stale)
+	controller, err := internalGitHub.NewController(internalGitHub.WithAuthMode("token"), internalGitHub.WithToken("token"),
+		internalGitHub.WithClientCache(cache))
+	require.NoError(t, err)
+	return controller, cache
+}
+
+func TestInstallationEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Action             string
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedCached     bool
+		ExpectedWarmed     bool
+	}{
+		{
+			Name:               "deleted",
+			Action:             "deleted",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+		},
+		{
+			Name:               "suspend",
+			Action:             "suspend",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+		},
+		{
+			Name:               "created",
+			Action:             "created",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedCached:     true,
+			ExpectedWarmed:     true,
+		},
+		{
+			Name:               "unsuspend",
+			Action:             "unsuspend",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedCached:     true,
+			ExpectedWarmed:     true,
+		},
+		{
+			Name:               "new_permissions_accepted",
+			Action:             "new_permissions_accepted",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedCached:     true,
+			ExpectedWarmed:     true,
+		},
+		{
+			Name:               "unsupported_action",
+			Action:             "renamed",
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+			ExpectedCached:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			stale := fake.clients(t)
+			controller, cache := newCachingController(t, stale)
+			bus := fake.bus(t, event.Installation, &github.InstallationEvent{
+				Action:       &tc.Action,
+				Installation: &github.Installation{ID: new(testInstallationID)},
+			})
+
+			// The default pre-processors run ahead of the installation processors
+			var logs bytes.Buffer
+			bus, err := processor.Process(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)), bus,
+				processor.NewDynamicPromotionPreProcessor(controller), processor.NewInstallationEventProcessor(controller))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.NotContains(t, logs.String(), "promoter key not found")
+			cached, found := cache.Get(testInstallationID)
+			assert.Equal(t, tc.ExpectedCached, found)
+			if found {
+				// Pre-warmed clients replace the stale ones
+				assert.Equal(t, tc.ExpectedWarmed, cached != stale)
+			}
+		})
+	}
+}
+
+func TestInstallationRepositoriesEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Action             string
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedWarmed     bool
+	}{
+		{
+			Name:               "added",
+			Action:             "added",
+			ExpectedSkipReason: promotion.SkipRepositoryEvent,
+			ExpectedWarmed:     true,
+		},
+		{
+			Name:               "removed",
+			Action:             "removed",
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			stale := fake.clients(t)
+			controller, cache := newCachingController(t, stale)
+			bus := fake.bus(t, event.InstallationRepositories, &github.InstallationRepositoriesEvent{
+				Action:            &tc.Action,
+				Installation:      &github.Installation{ID: new(testInstallationID)},
+				RepositoriesAdded: []*github.Repository{{FullName: new("owner/repo")}},
+			})
+
+			// The default pre-processors run ahead of the installation processors
+			var logs bytes.Buffer
+			bus, err := processor.Process(context.Background(), slog.New(slog.NewTextHandler(&logs, nil)), bus,
+				processor.NewDynamicPromotionPreProcessor(controller), processor.NewInstallationRepositoriesEventProcessor(controller))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.NotContains(t, logs.String(), "promoter key not found")
+			cached, found := cache.Get(testInstallationID)
+			require.True(t, found)
+			// The token of the stale clients does not cover the added repositories
+			assert.Equal(t, tc.ExpectedWarmed, cached != stale)
+		})
+	}
+}
+
+func TestReconcileRepositories(t *testing.T) {
+	restoreConfig(t)
+	config.Promotion.Push.CreateTargetRef = false
+	fake := newFakeGitHub(t)
+	fake.on(http.MethodGet, "/repos/owner/repo", http.StatusOK, github.Repository{
+		CustomProperties: map[string]any{config.Promotion.Dynamic.Key: "main, staging"},
+	})
+	fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+	fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(1, "main", "staging", "c0ffee"))
+	fake.onRef("main", "c0ffee")
+	fake.onRef("staging", "beef")
+	fake.onCompare("staging", "main", "ahead", 1)
+	bus := fake.bus(t, event.InstallationRepositories, &github.InstallationRepositoriesEvent{})
+
+	// The failure of a repository does not stop the reconciliation of the others
+	opened, err := processor.ReconcileRepositories(context.Background(), newController(t), helpers.NewNoopLogger(), bus, fake.clients(t), []*github.Repository{
+		{FullName: new("owner/missing")},
+		{FullName: new("owner/repo")},
+	})
+	assert.ErrorContains(t, err, "failed to get repository")
+	assert.Equal(t, 1, opened)
+	created := fake.received(http.MethodPost, "/repos/owner/repo/pulls")
+	require.Len(t, created, 1)
+	assert.Contains(t, created[0].Body, `"head":"refs/heads/main","base":"refs/heads/staging"`)
+}
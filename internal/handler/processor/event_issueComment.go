@@ -0,0 +1,178 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type issueCommentEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewIssueCommentEventProcessor initializes a Processor for handling ChatOps commands commented on promotion requests.
+// Commands are acknowledged with a reaction; the chat-ops feedback processor replies with their outcome.
+func NewIssueCommentEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &issueCommentEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *issueCommentEventProcessor) Name() string {
+	return "issue-comment"
+}
+
+func (p *issueCommentEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:issue-comment")
+}
+
+func (p *issueCommentEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing issue-comment event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.IssueComment) {
+		p.logger.Debug("issue_comment event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "issue_comment event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.IssueCommentEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.IssueCommentEvent got %T", evt)
+	}
+
+	if e.GetAction() != "created" || e.Issue == nil || !e.Issue.IsPullRequest() {
+		p.logger.Debug("ignoring comment that is not a new pull request comment...", slog.String("action", e.GetAction()))
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("issue comment action %q", e.GetAction()))
+		return bus, nil
+	}
+	if e.GetSender().GetType() == "Bot" {
+		// Replies of the app, and of other bots, never carry commands
+		p.logger.Debug("ignoring comment by a bot...", slog.String("sender", e.GetSender().GetLogin()))
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("comment by bot %s", e.GetSender().GetLogin()))
+		return bus, nil
+	}
+	name, ok := promotion.ParseCommand(e.GetComment().GetBody())
+	if !ok {
+		p.logger.Debug("ignoring comment without command...")
+		bus.Skip(promotion.SkipUnprocessableAction, "comment does not issue a command")
+		return bus, nil
+	}
+
+	command := &promotion.Command{
+		Name:      name,
+		CommentID: e.GetComment().GetID(),
+		Number:    e.GetIssue().GetNumber(),
+		Actor:     e.GetSender().GetLogin(),
+	}
+	logger := p.logger.With(slog.String("command", name), slog.Int("number", command.Number), slog.String("actor", command.Actor))
+
+	pr, err := p.githubController.GetPullRequest(ctx, bus.Context, command.Number)
+	if err != nil {
+		logger.Error("failed to get commented pull request", slog.Any("error", err))
+		return bus, err
+	}
+	if !bus.Context.Promoter.IsPromotionRequest(pr) {
+		logger.Debug("ignoring command on a non-promotion request")
+		bus.Skip(promotion.SkipNoPromotionRequest, fmt.Sprintf("pull request #%d is not a promotion request", command.Number))
+		return bus, nil
+	}
+	if pr.GetState() != "open" {
+		logger.Debug("ignoring command on a closed promotion request")
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("promotion request #%d is %s", command.Number, pr.GetState()))
+		return bus, nil
+	}
+	bus.Context.PullRequest = pr
+	bus.Context.HeadSHA = pr.Head.SHA
+	bus.Context.HeadRef = helpers.NormaliseRefPtr(*pr.Head.Ref)
+	bus.Context.BaseRef = helpers.NormaliseRefPtr(*pr.Base.Ref)
+
+	// Commands are authorised against the role required by the target stage
+	minRole := config.Promotion.Stage(*bus.Context.BaseRef).MinRole
+	role, err := p.githubController.GetPermissionLevel(ctx, bus.Context, command.Actor)
+	if err != nil {
+		logger.Error("failed to get commenter role", slog.Any("error", err))
+		return bus, err
+	}
+	if !promotion.HasRole(role, minRole) {
+		logger.Info("denying command to commenter lacking the required role", slog.String("role", role), slog.String("minRole", minRole))
+		p.acknowledge(ctx, bus, command, "-1", fmt.Sprintf("@%s `/%s` requires the `%s` role on `%s`.", command.Actor, name, minRole, *bus.Context.BaseRef))
+		bus.Skip(promotion.SkipPermissionDenied, fmt.Sprintf("%s has role %q, %s requires %q", command.Actor, role, *bus.Context.BaseRef, minRole))
+		return bus, nil
+	}
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
+
+	logger.Info("processing command...")
+	bus.Response = models.Response{Body: fmt.Sprintf("Command /%s accepted", name), StatusCode: http.StatusOK}
+	switch name {
+	case promotion.CommandHold:
+		if err = p.githubController.SetHold(ctx, bus.Context, true); err != nil {
+			logger.Error("failed to hold promotion request", slog.Any("error", err))
+			return bus, err
+		}
+		p.acknowledge(ctx, bus, command, "+1", fmt.Sprintf("⏸️ Promotion held by @%s. Comment `/unhold` to resume it.", command.Actor))
+		bus.Skip(promotion.SkipOnHold, fmt.Sprintf("held by %s", command.Actor))
+		return bus, nil
+	case promotion.CommandUnhold:
+		if err = p.githubController.SetHold(ctx, bus.Context, false); err != nil {
+			logger.Error("failed to release promotion request", slog.Any("error", err))
+			return bus, err
+		}
+		bus.Context.PullRequest.Labels = slices.DeleteFunc(pr.Labels, func(label *github.Label) bool {
+			return label.GetName() == config.Promotion.ChatOps.HoldLabel
+		})
+	case promotion.CommandExplain:
+		// The post-processors evaluate the promotion without applying it
+		if bus.Plan == nil {
+			bus.Plan = new(promotion.Plan)
+		}
+	case promotion.CommandRetryFeedback:
+		bus.Resolved = true
+		bus.EventStatus = promotion.Pending
+		if sha, err := p.githubController.GetPromotionTargetRefSHA(ctx, bus.Context); err == nil && sha == *bus.Context.HeadSHA {
+			bus.EventStatus = promotion.Success
+		}
+	}
+
+	bus.Command = command
+	p.acknowledge(ctx, bus, command, "+1", "")
+	return bus, nil
+}
+
+// acknowledge reacts to the command comment and, if a reply is given, answers it. Failures are logged, not returned,
+// as the command itself is unaffected.
+func (p *issueCommentEventProcessor) acknowledge(ctx context.Context, bus *promotion.Bus, command *promotion.Command, reaction, reply string) {
+	if err := p.githubController.ReactToComment(ctx, bus.Context, command.CommentID, reaction); err != nil {
+		p.logger.Warn("failed to react to command", slog.Any("error", err))
+	}
+	if reply == "" {
+		return
+	}
+	if err := p.githubController.CommentOnPullRequest(ctx, bus.Context, command.Number, reply); err != nil {
+		p.logger.Warn("failed to reply to command", slog.Any("error", err))
+	}
+}
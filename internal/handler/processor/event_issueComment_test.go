@@ -0,0 +1,97 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueCommentEventProcessorAuthorisesCommands(t *testing.T) {
+	testCases := []struct {
+		Name             string
+		MinRole          string
+		RoleName         string
+		Permission       string
+		ExpectedReaction string
+	}{
+		{
+			Name:             "base_role",
+			MinRole:          "write",
+			RoleName:         "write",
+			Permission:       "write",
+			ExpectedReaction: "+1",
+		},
+		{
+			Name:             "insufficient_base_role",
+			MinRole:          "write",
+			RoleName:         "triage",
+			Permission:       "read",
+			ExpectedReaction: "-1",
+		},
+		{
+			Name:             "custom_role_inheriting_write",
+			MinRole:          "write",
+			RoleName:         "release-manager",
+			Permission:       "write",
+			ExpectedReaction: "+1",
+		},
+		{
+			Name:             "custom_role_inheriting_read",
+			MinRole:          "write",
+			RoleName:         "auditor",
+			Permission:       "read",
+			ExpectedReaction: "-1",
+		},
+		{
+			Name:             "unknown_min_role",
+			MinRole:          "maintainer",
+			RoleName:         "admin",
+			Permission:       "admin",
+			ExpectedReaction: "-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.MinRole = tc.MinRole })
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls/1", http.StatusOK, pullRequest(1, "main", "staging", "c0ffee"))
+			fake.on(http.MethodGet, "/repos/owner/repo/collaborators/alice/permission", http.StatusOK, github.RepositoryPermissionLevel{
+				Permission: &tc.Permission,
+				RoleName:   &tc.RoleName,
+			})
+			fake.on(http.MethodPost, "/repos/owner/repo/issues/comments/7/reactions", http.StatusCreated, github.Reaction{})
+			fake.on(http.MethodPost, "/repos/owner/repo/issues/1/comments", http.StatusCreated, github.IssueComment{})
+			bus := fake.bus(t, event.IssueComment, &github.IssueCommentEvent{
+				Action: new("created"),
+				Issue: &github.Issue{
+					Number:           new(1),
+					PullRequestLinks: &github.PullRequestLinks{URL: new("https://api.github.com/repos/owner/repo/pulls/1")},
+				},
+				Comment: &github.IssueComment{ID: new(int64(7)), Body: new("/explain")},
+				Sender:  &github.User{Login: new("alice"), Type: new("User")},
+			}, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewIssueCommentEventProcessor(newController(t)))
+			require.NoError(t, err)
+			reactions := fake.received(http.MethodPost, "/repos/owner/repo/issues/comments/7/reactions")
+			require.Len(t, reactions, 1)
+			assert.Contains(t, reactions[0].Body, `"content":"`+tc.ExpectedReaction+`"`)
+			if tc.ExpectedReaction == "-1" {
+				assert.Equal(t, promotion.SkipPermissionDenied, skipReason(bus))
+				return
+			}
+			assert.Empty(t, skipReason(bus))
+			assert.Equal(t, promotion.CommandExplain, bus.Command.Name)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/google/go-github/v88/github"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+// mergeGroupRefPattern matches the head ref of a merge group, e.g. refs/heads/gh-readonly-queue/staging/pr-42-<sha>.
+var mergeGroupRefPattern = regexp.MustCompile(`gh-readonly-queue/.+/pr-(\d+)-[0-9a-f]+$`)
+
+type mergeGroupEventProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewMergeGroupEventProcessor initializes a Processor for handling merge group events with optional configurations.
+// It tracks the promotion requests enqueued by the merge-queue strategy and reports their outcome through the feedback processors.
+func NewMergeGroupEventProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &mergeGroupEventProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *mergeGroupEventProcessor) Name() string {
+	return "merge-group"
+}
+
+func (p *mergeGroupEventProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("processor:merge-group")
+}
+
+func (p *mergeGroupEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	p.logger.Debug("processing merge-group event...")
+
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+	evt := parsedBus.Event
+
+	if !event.IsEnabled(event.MergeGroup) {
+		p.logger.Debug("merge_group event is not enabled. skipping...")
+		bus.Skip(promotion.SkipEventDisabled, "merge_group event is not enabled")
+		return bus, nil
+	}
+
+	e, ok := evt.(*github.MergeGroupEvent)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.MergeGroupEvent got %T", evt)
+	}
+
+	action := e.GetAction()
+	if action != "checks_requested" && action != "destroyed" {
+		p.logger.Debug("ignoring merge group event action...", slog.String("action", action))
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("merge group action %q", action))
+		return bus, nil
+	}
+
+	headRef := e.GetMergeGroup().GetHeadRef()
+	match := mergeGroupRefPattern.FindStringSubmatch(headRef)
+	if match == nil {
+		p.logger.Info("ignoring merge group with unexpected head ref...", slog.String("headRef", headRef))
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("merge group head ref %q does not reference a pull request", headRef))
+		return bus, nil
+	}
+	number, _ := strconv.Atoi(match[1])
+
+	pr, err := p.githubController.GetPullRequest(ctx, bus.Context, number)
+	if err != nil {
+		p.logger.Error("failed to get merge group pull request", slog.Int("number", number), slog.Any("error", err))
+		return bus, err
+	}
+	if !bus.Context.Promoter.IsPromotionRequest(pr) {
+		p.logger.Debug("ignoring merge group of a non-promotion request", slog.Int("number", number))
+		bus.Skip(promotion.SkipNoPromotionRequest, fmt.Sprintf("pull request #%d is not a promotion request", number))
+		return bus, nil
+	}
+	bus.Context.PullRequest = pr
+	bus.Context.HeadSHA = pr.Head.SHA
+	bus.Context.HeadRef = helpers.NormaliseRefPtr(*pr.Head.Ref)
+	bus.Context.BaseRef = helpers.NormaliseRefPtr(*pr.Base.Ref)
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
+
+	// The merge queue applies the promotion, the merge group event only reports its outcome
+	bus.Resolved = true
+	logger := p.logger.With(slog.Int("number", number), slog.String("baseRef", *bus.Context.BaseRef))
+	switch reason := e.GetReason(); {
+	case action == "checks_requested":
+		logger.Info("promotion request entered the merge queue")
+		bus.Response = models.Response{Body: "Promotion queued", StatusCode: http.StatusOK}
+		bus.EventStatus = promotion.Pending
+	case reason == "merged":
+		logger.Info("promotion request merged by the merge queue")
+		bus.Response = models.Response{Body: "Promotion complete", StatusCode: http.StatusOK}
+		bus.EventStatus = promotion.Success
+	default:
+		logger.Info("promotion request left the merge queue", slog.String("reason", reason))
+		bus.Error = fmt.Errorf("promotion request left the merge queue: %s", reason)
+		bus.Response = models.Response{Body: bus.Error.Error(), StatusCode: http.StatusOK}
+		bus.EventStatus = promotion.Failure
+	}
+	return bus, nil
+}
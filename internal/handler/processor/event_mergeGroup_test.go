@@ -0,0 +1,103 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeGroupEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Action             string
+		Reason             string
+		HeadRef            string
+		PullRequest        *github.PullRequest
+		ExpectedStatus     promotion.EventStatus
+		ExpectedSkipReason promotion.SkipReason
+	}{
+		{
+			Name:           "checks_requested",
+			Action:         "checks_requested",
+			HeadRef:        "refs/heads/gh-readonly-queue/staging/pr-1-c0ffee",
+			PullRequest:    pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus: promotion.Pending,
+		},
+		{
+			Name:           "destroyed_merged",
+			Action:         "destroyed",
+			Reason:         "merged",
+			HeadRef:        "refs/heads/gh-readonly-queue/staging/pr-1-c0ffee",
+			PullRequest:    pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus: promotion.Success,
+		},
+		{
+			Name:           "destroyed_invalidated",
+			Action:         "destroyed",
+			Reason:         "invalidated",
+			HeadRef:        "refs/heads/gh-readonly-queue/staging/pr-1-c0ffee",
+			PullRequest:    pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus: promotion.Failure,
+		},
+		{
+			Name:               "non_promotion_request",
+			Action:             "checks_requested",
+			HeadRef:            "refs/heads/gh-readonly-queue/staging/pr-1-c0ffee",
+			PullRequest:        pullRequest(1, "feature", "staging", "c0ffee"),
+			ExpectedStatus:     promotion.Skipped,
+			ExpectedSkipReason: promotion.SkipNoPromotionRequest,
+		},
+		{
+			Name:               "unexpected_head_ref",
+			Action:             "checks_requested",
+			HeadRef:            "refs/heads/staging",
+			ExpectedStatus:     promotion.Skipped,
+			ExpectedSkipReason: promotion.SkipUnprocessableState,
+		},
+		{
+			Name:               "unsupported_action",
+			Action:             "created",
+			ExpectedStatus:     promotion.Skipped,
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls/1", http.StatusOK, tc.PullRequest)
+			bus := fake.bus(t, event.MergeGroup, &github.MergeGroupEvent{
+				Action:     &tc.Action,
+				Reason:     &tc.Reason,
+				MergeGroup: &github.MergeGroup{HeadRef: &tc.HeadRef},
+			}, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewMergeGroupEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedStatus, bus.EventStatus)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			if tc.ExpectedSkipReason != "" {
+				assert.False(t, bus.Resolved)
+				return
+			}
+			// The merge queue applies the promotion, leaving the fast-forwarder out
+			assert.True(t, bus.Resolved)
+			assert.Equal(t, http.StatusOK, bus.Response.StatusCode)
+			assert.Equal(t, "staging", helpers.String(bus.Context.BaseRef))
+
+			bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedStatus, bus.EventStatus)
+			assert.Empty(t, fake.receivedPrefix(http.MethodPatch, "/repos/owner/repo/git/refs/"))
+			assert.Empty(t, fake.received(http.MethodPost, "/graphql"))
+		})
+	}
+}
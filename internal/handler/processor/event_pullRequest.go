@@ -1,12 +1,16 @@
 package processor
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/google/go-github/v88/github"
 	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
 )
 
@@ -15,6 +19,10 @@ type pullRequestEventProcessor struct {
 	githubController *internalGitHub.Controller
 }
 
+func (p *pullRequestEventProcessor) Name() string {
+	return "pull-request"
+}
+
 func (p *pullRequestEventProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:pull-request")
 }
@@ -26,7 +34,7 @@ func NewPullRequestEventProcessor(githubController *internalGitHub.Controller, o
 	return _inst
 }
 
-func (p *pullRequestEventProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *pullRequestEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing pull request event...")
 
 	if p.githubController == nil {
@@ -41,7 +49,7 @@ func (p *pullRequestEventProcessor) Process(req any) (bus *promotion.Bus, err er
 
 	if !event.IsEnabled(event.PullRequest) {
 		p.logger.Debug("pull_request event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "pull_request event is not enabled")
 		return bus, nil
 	}
 
@@ -52,7 +60,7 @@ func (p *pullRequestEventProcessor) Process(req any) (bus *promotion.Bus, err er
 
 	if *e.PullRequest.Draft {
 		p.logger.Info("ignoring draft pull request...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipDraftPullRequest, "pull request is a draft")
 		return bus, nil
 	}
 
@@ -63,23 +71,76 @@ func (p *pullRequestEventProcessor) Process(req any) (bus *promotion.Bus, err er
 
 	switch *e.Action {
 	case "closed":
-		if e.PullRequest.GetMerged() {
-			p.logger.Debug("processing pull request closed and merged...")
-			// send feedback commit status: success
-			bus.EventStatus = promotion.Success
+		if !e.PullRequest.GetMerged() {
+			bus.Skip(promotion.SkipUnprocessableAction, "pull request closed without merge")
+			return bus, nil
+		}
+		if !bus.Context.Promoter.IsPromotionRequest(e.PullRequest) {
+			bus.Skip(promotion.SkipNoPromotionRequest, fmt.Sprintf("merged pull request #%d is not a promotion request", e.PullRequest.GetNumber()))
+			return bus, nil
+		}
+		p.logger.Debug("processing pull request closed and merged...")
+		// The feedback of the merge is sent on the merge commit, which the base ref now points at
+		if e.PullRequest.MergeCommitSHA != nil {
+			bus.Context.HeadSHA = e.PullRequest.MergeCommitSHA
+		}
+		if err = bus.LockBaseRef(ctx); err != nil {
+			return bus, err
 		}
-		bus.EventStatus = promotion.Skipped
+		if err = p.cascade(ctx, bus, e.PullRequest); err != nil {
+			bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
+			return bus, err
+		}
+		// The promotion was merged manually: send feedback commit status: success
+		bus.Resolved = true
+		bus.EventStatus = promotion.Success
+		bus.Response = models.Response{Body: "Promotion complete", StatusCode: http.StatusOK}
 		return bus, nil
 	case "opened":
 		bus.EventStatus = promotion.Pending
-		return bus, nil
+		// Serialise the remaining processing of events targeting the same base ref
+		return bus, bus.LockBaseRef(ctx)
 	case "edited", "ready_for_review", "reopened", "unlocked":
 		p.logger.Info("ignoring pull request event...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("pull request action %q", *e.Action))
 		return bus, nil
 	default:
 		p.logger.Info("ignoring pull request with unprocessable event...", slog.String("action", *e.Action))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableAction, fmt.Sprintf("pull request action %q", *e.Action))
 		return bus, nil
 	}
 }
+
+// cascade opens the promotion requests of the next stages from the merged promotion request, like a push to its base ref would.
+func (p *pullRequestEventProcessor) cascade(ctx context.Context, bus *promotion.Bus, merged *github.PullRequest) error {
+	nextStages, isPromotable := bus.Context.Promoter.IsPromotableRef(*merged.Base.Ref)
+	if !isPromotable {
+		p.logger.Debug("merged promotion request targets a last stage")
+		return nil
+	}
+	for _, nextStage := range nextStages {
+		if err := p.cascadeInto(ctx, bus, merged, nextStage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cascadeInto opens the promotion request from the base ref of the merged promotion request into the given next stage.
+func (p *pullRequestEventProcessor) cascadeInto(ctx context.Context, bus *promotion.Bus, merged *github.PullRequest, nextStage string) error {
+	pCtx := *bus.Context
+	pCtx.HeadRef = helpers.NormaliseFullRefPtr(*merged.Base.Ref)
+	pCtx.HeadSHA = merged.MergeCommitSHA
+	pCtx.BaseRef = helpers.NormaliseFullRefPtr(nextStage)
+	pCtx.PullRequest = nil
+	next := &promotion.Bus{Context: &pCtx, Repository: bus.Repository, Locker: bus.Locker}
+
+	// Serialise with the push event of the merge, which targets the same base ref
+	if err := next.LockBaseRef(ctx); err != nil {
+		return err
+	}
+	defer next.Unlock()
+
+	p.logger.Info("cascading promotion to the next stage...", slog.String("headRef", *pCtx.HeadRef), slog.String("baseRef", nextStage))
+	return openPromotionRequest(ctx, p.githubController, p.logger, next)
+}
@@ -1,12 +1,17 @@
 package processor
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
 
 	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
 	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
 )
 
@@ -22,11 +27,15 @@ func NewPullRequestReviewEventProcessor(githubController *internalGitHub.Control
 	return _inst
 }
 
+func (p *pullRequestReviewEventProcessor) Name() string {
+	return "pull-request-review"
+}
+
 func (p *pullRequestReviewEventProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:pull-request-review")
 }
 
-func (p *pullRequestReviewEventProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *pullRequestReviewEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing pull request review event...")
 
 	if p.githubController == nil {
@@ -41,7 +50,7 @@ func (p *pullRequestReviewEventProcessor) Process(req any) (bus *promotion.Bus,
 
 	if !event.IsEnabled(event.PullRequestReview) {
 		p.logger.Debug("pull_request_review event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "pull_request_review event is not enabled")
 		return bus, nil
 	}
 
@@ -54,7 +63,7 @@ func (p *pullRequestReviewEventProcessor) Process(req any) (bus *promotion.Bus,
 
 	if *e.Review.State != "approved" {
 		p.logger.Info("ignoring non-approved pull request review event with unprocessable review state...", slog.String("state", *e.Review.State))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("review state %q", *e.Review.State))
 		return bus, nil
 	}
 
@@ -62,5 +71,38 @@ func (p *pullRequestReviewEventProcessor) Process(req any) (bus *promotion.Bus,
 	bus.Context.HeadRef = helpers.NormaliseRefPtr(*e.PullRequest.Head.Ref)
 	bus.Context.HeadSHA = e.PullRequest.Head.SHA
 	bus.Context.PullRequest = e.PullRequest
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
+
+	approval, err := p.evaluateReviews(ctx, bus)
+	if err != nil {
+		p.logger.Error("failed to evaluate reviews", slog.Any("error", err))
+		return bus, err
+	}
+	if !approval.Approved() {
+		p.logger.Info("ignoring approval not satisfying the review policy", slog.String("approval", approval.String()))
+		bus.Response = models.Response{Body: fmt.Sprintf("Promotion not approved: %s", approval), StatusCode: http.StatusOK}
+		bus.Skip(promotion.SkipNotApproved, approval.String())
+		return bus, nil
+	}
 	return bus, nil
 }
+
+// evaluateReviews evaluates the reviews of the promotion request against the review policy of the target stage.
+func (p *pullRequestReviewEventProcessor) evaluateReviews(ctx context.Context, bus *promotion.Bus) (promotion.Approval, error) {
+	policy := config.Promotion.Stage(helpers.NormaliseRef(*bus.Context.BaseRef)).Reviews
+	reviews, err := p.githubController.ListPullRequestReviews(ctx, bus.Context)
+	if err != nil {
+		return promotion.Approval{}, err
+	}
+	teamMembers := make(map[string][]string, len(policy.RequiredTeams))
+	for _, team := range policy.RequiredTeams {
+		if teamMembers[team], err = p.githubController.ListTeamMembers(ctx, bus.Context, team); err != nil {
+			return promotion.Approval{}, err
+		}
+	}
+	return promotion.EvaluateReviews(policy, *bus.Context.HeadSHA, reviews, teamMembers), nil
+}
@@ -0,0 +1,110 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// review returns a review of the test promotion request submitted by the login on the commit, the given number of
+// minutes after the epoch.
+func review(login, state, commitID string, minute int) *github.PullRequestReview {
+	return &github.PullRequestReview{
+		User:        &github.User{Login: &login},
+		State:       &state,
+		CommitID:    &commitID,
+		SubmittedAt: &github.Timestamp{Time: time.Unix(int64(minute)*60, 0)},
+	}
+}
+
+func TestPullRequestReviewEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		Policy              config.ReviewPolicy
+		Reviews             []*github.PullRequestReview
+		ExpectedSkipReason  promotion.SkipReason
+		ExpectedBody        string
+		ExpectedFastForward bool
+	}{
+		{
+			Name:                "approved",
+			Policy:              config.ReviewPolicy{MinApprovals: 1, RequiredTeams: []string{"sre"}},
+			Reviews:             []*github.PullRequestReview{review("alice", "APPROVED", "c0ffee", 1)},
+			ExpectedFastForward: true,
+		},
+		{
+			Name:   "unmet_minimum",
+			Policy: config.ReviewPolicy{MinApprovals: 2},
+			Reviews: []*github.PullRequestReview{
+				review("alice", "APPROVED", "c0ffee", 1),
+				review("bob", "COMMENTED", "c0ffee", 2),
+			},
+			ExpectedSkipReason: promotion.SkipNotApproved,
+			ExpectedBody:       "missing approvals: 1",
+		},
+		{
+			Name:               "missing_team",
+			Policy:             config.ReviewPolicy{MinApprovals: 1, RequiredTeams: []string{"sre"}},
+			Reviews:            []*github.PullRequestReview{review("bob", "APPROVED", "c0ffee", 1)},
+			ExpectedSkipReason: promotion.SkipNotApproved,
+			ExpectedBody:       "missing team approvals: sre",
+		},
+		{
+			Name:   "changes_requested",
+			Policy: config.ReviewPolicy{MinApprovals: 1},
+			Reviews: []*github.PullRequestReview{
+				review("alice", "APPROVED", "c0ffee", 1),
+				review("bob", "CHANGES_REQUESTED", "c0ffee", 2),
+			},
+			ExpectedSkipReason: promotion.SkipNotApproved,
+			ExpectedBody:       "changes requested: bob",
+		},
+		{
+			Name:               "stale_approval",
+			Policy:             config.ReviewPolicy{MinApprovals: 1, DismissStale: true},
+			Reviews:            []*github.PullRequestReview{review("alice", "APPROVED", "beef", 1)},
+			ExpectedSkipReason: promotion.SkipNotApproved,
+			ExpectedBody:       "missing approvals: 1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.Reviews = tc.Policy })
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls/1/reviews", http.StatusOK, tc.Reviews)
+			fake.on(http.MethodGet, "/orgs/owner/teams/sre/members", http.StatusOK, []*github.User{{Login: new("alice")}})
+			fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+			evt := &github.PullRequestReviewEvent{
+				Action:      new("submitted"),
+				Review:      &github.PullRequestReview{State: new("approved")},
+				PullRequest: pullRequest(1, "main", "staging", "c0ffee"),
+			}
+			bus := fake.bus(t, event.PullRequestReview, evt, "main", "staging", "production")
+			controller := newController(t)
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewPullRequestReviewEventProcessor(controller))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.Contains(t, bus.Response.Body, tc.ExpectedBody)
+			// As the handler does, the fast-forwarder only runs if the event was not skipped
+			if bus.EventStatus != promotion.Skipped {
+				bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(controller))
+				require.NoError(t, err)
+				assert.Equal(t, promotion.Success, bus.EventStatus)
+			}
+			assert.Len(t, fake.received(http.MethodGet, "/repos/owner/repo/pulls/1/reviews"), 1)
+			assert.Equal(t, tc.ExpectedFastForward, len(fake.received(http.MethodPatch, fastForwardPath)) > 0)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPullRequestEventProcessorClosed(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Head               string
+		Base               string
+		Merged             bool
+		Graph              string
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedCreated    []string
+	}{
+		{
+			Name:            "merged_promotion_request",
+			Head:            "staging",
+			Base:            "canary",
+			Merged:          true,
+			Graph:           "main > staging > canary > production",
+			ExpectedCreated: []string{`"head":"refs/heads/canary","base":"refs/heads/production"`},
+		},
+		{
+			Name:   "merged_promotion_request_fans_out",
+			Head:   "main",
+			Base:   "staging",
+			Merged: true,
+			Graph:  "main > staging > canary-eu, canary-us",
+			ExpectedCreated: []string{
+				`"head":"refs/heads/staging","base":"refs/heads/canary-eu"`,
+				`"head":"refs/heads/staging","base":"refs/heads/canary-us"`,
+			},
+		},
+		{
+			Name:   "merged_promotion_request_into_last_stage",
+			Head:   "canary",
+			Base:   "production",
+			Merged: true,
+			Graph:  "main > staging > canary > production",
+		},
+		{
+			Name:               "closed_without_merge",
+			Head:               "staging",
+			Base:               "canary",
+			Graph:              "main > staging > canary > production",
+			ExpectedSkipReason: promotion.SkipUnprocessableAction,
+		},
+		{
+			Name:               "merged_non_promotion_request",
+			Head:               "feature",
+			Base:               "main",
+			Merged:             true,
+			Graph:              "main > staging > canary > production",
+			ExpectedSkipReason: promotion.SkipNoPromotionRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+			fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(2, tc.Base, "production", "5ca1ab1e"))
+			for _, stage := range []string{"production", "canary-eu", "canary-us"} {
+				fake.onRef(stage, "beef")
+			}
+			pr := pullRequest(1, tc.Head, tc.Base, "c0ffee")
+			pr.State = new("closed")
+			pr.Draft = new(false)
+			pr.Merged = &tc.Merged
+			if tc.Merged {
+				pr.MergeCommitSHA = new("5ca1ab1e")
+			}
+			bus := fake.bus(t, event.PullRequest, &github.PullRequestEvent{Action: new("closed"), PullRequest: pr})
+			promoter, err := promotion.NewGraphPromoter("test", tc.Graph)
+			require.NoError(t, err)
+			bus.Context.Promoter = promoter
+
+			bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewPullRequestEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			created := fake.received(http.MethodPost, "/repos/owner/repo/pulls")
+			require.Len(t, created, len(tc.ExpectedCreated))
+			for i, expected := range tc.ExpectedCreated {
+				assert.Contains(t, created[i].Body, expected)
+			}
+			if tc.ExpectedSkipReason == "" {
+				assert.True(t, bus.Resolved)
+				assert.Equal(t, promotion.Success, bus.EventStatus)
+				assert.Equal(t, http.StatusOK, bus.Response.StatusCode)
+				assert.Equal(t, "5ca1ab1e", helpers.String(bus.Context.HeadSHA))
+			} else {
+				assert.False(t, bus.Resolved)
+				assert.Empty(t, fake.receivedPrefix(http.MethodPost, "/repos/owner/repo/"))
+			}
+		})
+	}
+}
+
+func TestPullRequestEventProcessorCascadesFromMergeCommit(t *testing.T) {
+	fake := newFakeGitHub(t)
+	fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+	fake.onRef("production", "beef")
+	pr := pullRequest(1, "staging", "canary", "c0ffee")
+	pr.State = new("closed")
+	pr.Draft = new(false)
+	pr.Merged = new(true)
+	pr.MergeCommitSHA = new("5ca1ab1e")
+	bus := fake.bus(t, event.PullRequest, &github.PullRequestEvent{Action: new("closed"), PullRequest: pr}, "main", "staging", "canary", "production")
+	plan := new(promotion.Plan)
+
+	bus, err := processor.Process(promotion.ContextWithPlan(context.Background(), plan), helpers.NewNoopLogger(), bus, processor.NewPullRequestEventProcessor(newController(t)))
+	require.NoError(t, err)
+	assert.Equal(t, promotion.Success, bus.EventStatus)
+	require.Len(t, plan.Actions(), 1)
+	assert.Equal(t, "create-pull-request", plan.Actions()[0].Action)
+	assert.Equal(t, "refs/heads/production", plan.Actions()[0].Ref)
+	assert.Equal(t, "5ca1ab1e", plan.Actions()[0].SHA)
+}
@@ -1,11 +1,11 @@
 package processor
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
-	"net/http"
 
 	"github.com/google/go-github/v88/github"
-	"github.com/isometry/gh-promotion-app/internal/config"
 	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
@@ -25,11 +25,15 @@ func NewPushEventProcessor(githubController *internalGitHub.Controller, opts ...
 	return _inst
 }
 
+func (p *pushEventProcessor) Name() string {
+	return "push"
+}
+
 func (p *pushEventProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:push")
 }
 
-func (p *pushEventProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *pushEventProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing push event...")
 
 	if p.githubController == nil {
@@ -44,7 +48,7 @@ func (p *pushEventProcessor) Process(req any) (bus *promotion.Bus, err error) {
 
 	if !event.IsEnabled(event.Push) {
 		p.logger.Debug("push event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "push event is not enabled")
 		return bus, nil
 	}
 
@@ -56,39 +60,41 @@ func (p *pushEventProcessor) Process(req any) (bus *promotion.Bus, err error) {
 	bus.Context.HeadRef = e.Ref
 	bus.Context.HeadSHA = e.After
 
-	if nextStage, isPromotable := bus.Context.Promoter.IsPromotableRef(*e.Ref); isPromotable {
-		bus.Context.BaseRef = helpers.NormaliseFullRefPtr(nextStage)
-	} else {
+	nextStages, isPromotable := bus.Context.Promoter.IsPromotableRef(*e.Ref)
+	if !isPromotable {
 		p.logger.Info("ignoring push event on non-promotion branch", slog.String("headRef", *bus.Context.HeadRef))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipNotPromotionBranch, fmt.Sprintf("%s is not a promotion source", *bus.Context.HeadRef))
 		return bus, nil
 	}
 
-	// Create missing target ref if the feature is enabled and the target ref does not exist
-	if config.Promotion.Push.CreateTargetRef && !p.githubController.PromotionTargetRefExists(bus.Context) {
-		if _, err = p.githubController.CreatePromotionTargetRef(bus.Context); err != nil {
-			p.logger.Error("failed to create target ref", slog.Any("error", err))
-			bus.Response = models.Response{Body: err.Error(), StatusCode: http.StatusInternalServerError}
+	// Stages fanning out open a promotion request into each next stage: all but the first with a fork of the bus,
+	// each serialised with the other events targeting its stage
+	for _, nextStage := range nextStages[1:] {
+		fork := bus.Fork()
+		fork.Context.BaseRef = helpers.NormaliseFullRefPtr(nextStage)
+		if err = fork.LockBaseRef(ctx); err != nil {
+			return bus, err
+		}
+		err = openPromotionRequest(ctx, p.githubController, p.logger, fork)
+		fork.Unlock()
+		if err != nil {
+			bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
 			return bus, err
 		}
+		fork.EventStatus = promotion.Pending
+		bus.Forks = append(bus.Forks, fork)
 	}
+	bus.Context.BaseRef = helpers.NormaliseFullRefPtr(nextStages[0])
 
-	if bus.Context.PullRequest, _ = p.githubController.FindPullRequest(bus.Context); bus.Context.PullRequest != nil {
-		// PR already exists covering this push event
-		p.logger.Info("skipping recreation of existing promotion request...", slog.String("url", *bus.Context.PullRequest.URL))
-		// send feedback commit status: pending
-		bus.EventStatus = promotion.Pending
-		return bus, nil
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
 	}
 
-	p.logger.Debug("creating promotion PR...")
-	bus.Context.PullRequest, err = p.githubController.CreatePullRequest(bus)
-	if err != nil {
-		p.logger.Error("failed to create promotion PR", slog.Any("error", err))
-		bus.Response = models.Response{Body: err.Error(), StatusCode: http.StatusInternalServerError}
+	if err = openPromotionRequest(ctx, p.githubController, p.logger, bus); err != nil {
+		bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
 		return bus, err
 	}
-	p.logger.Info("created promotion PR", slog.String("url", *bus.Context.PullRequest.URL))
 	// send feedback commit status: pending
 	bus.EventStatus = promotion.Pending
 	return bus, nil
@@ -1,6 +1,8 @@
 package processor
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/google/go-github/v88/github"
@@ -22,11 +24,15 @@ func NewStatusEventProcessor(githubController *internalGitHub.Controller, opts .
 	return _inst
 }
 
+func (p *statusProcessor) Name() string {
+	return "status"
+}
+
 func (p *statusProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:status")
 }
 
-func (p *statusProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *statusProcessor) Process(_ context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing status event...")
 
 	if p.githubController == nil {
@@ -41,7 +47,7 @@ func (p *statusProcessor) Process(req any) (bus *promotion.Bus, err error) {
 
 	if !event.IsEnabled(event.Status) {
 		p.logger.Debug("status event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "status event is not enabled")
 		return bus, nil
 	}
 
@@ -56,7 +62,7 @@ func (p *statusProcessor) Process(req any) (bus *promotion.Bus, err error) {
 	state := *e.State
 	if state != "success" {
 		p.logger.Info("ignoring non-success status event with unprocessable status event state...", slog.String("state", state))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("status state %q", state))
 		return bus, nil
 	}
 
@@ -1,9 +1,16 @@
 package processor
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
 	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
@@ -22,11 +29,15 @@ func NewWorkflowRunEventProcessor(githubController *internalGitHub.Controller, o
 	return _inst
 }
 
+func (p *workflowRunProcessor) Name() string {
+	return "workflow-run"
+}
+
 func (p *workflowRunProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("processor:workflow-run")
 }
 
-func (p *workflowRunProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *workflowRunProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing workflow run event...")
 
 	if p.githubController == nil {
@@ -41,13 +52,13 @@ func (p *workflowRunProcessor) Process(req any) (bus *promotion.Bus, err error)
 
 	if !event.IsEnabled(event.WorkflowRun) {
 		p.logger.Debug("workflow_run event is not enabled. skipping...")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipEventDisabled, "workflow_run event is not enabled")
 		return bus, nil
 	}
 
 	e, ok := evt.(*github.WorkflowRunEvent)
 	if !ok {
-		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.DeploymentStatusEvent got %T", evt)
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *github.WorkflowRunEvent got %T", evt)
 	}
 
 	p.logger.Debug("processing workflow run event...")
@@ -55,31 +66,94 @@ func (p *workflowRunProcessor) Process(req any) (bus *promotion.Bus, err error)
 	status := *e.WorkflowRun.Status
 	if status != string(internalGitHub.CheckRunStatusCompleted) {
 		p.logger.Info("ignoring incomplete workflow run event with unprocessable workflow run status...", slog.String("status", status))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("workflow run status %q", status))
 		return bus, nil
 	}
 
 	conclusion := *e.WorkflowRun.Conclusion
 	if conclusion != string(internalGitHub.CheckRunConclusionSuccess) {
 		p.logger.Info("ignoring unsuccessful workflow run event with unprocessable workflow run conclusion...", slog.String("conclusion", conclusion))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipUnprocessableState, fmt.Sprintf("workflow run conclusion %q", conclusion))
 		return bus, nil
 	}
 
 	bus.Context.HeadSHA = e.WorkflowRun.HeadSHA
 
+	// Workflow runs of pushes to a stage carry no pull request: the target stages follow from the head branch
+	targetStages, _ := bus.Context.Promoter.IsPromotableRef(e.WorkflowRun.GetHeadBranch())
+	var prs []*github.PullRequest
 	for _, pr := range e.WorkflowRun.PullRequests {
 		if *pr.Head.SHA == *bus.Context.HeadSHA && bus.Context.Promoter.IsPromotionRequest(pr) {
-			bus.Context.BaseRef = helpers.NormaliseRefPtr(*pr.Base.Ref)
-			bus.Context.HeadRef = helpers.NormaliseRefPtr(*pr.Head.Ref)
-			break
+			prs = append(prs, pr)
+		}
+	}
+	if len(prs) > 0 {
+		targetStages = nil
+		for _, pr := range prs {
+			targetStages = append(targetStages, helpers.NormaliseRef(*pr.Base.Ref))
 		}
 	}
 
-	if bus.Context.BaseRef == nil || bus.Context.HeadRef == nil {
-		p.logger.Info("ignoring check suite event without matching promotion request...")
+	// Stages fanning out are promoted into by the workflow runs matching the filter of each target stage
+	matches := func(targetStage string) bool {
+		return matchesWorkflowRun(config.Promotion.Stage(targetStage).WorkflowRun, e.WorkflowRun)
+	}
+	if len(targetStages) > 0 && !slices.ContainsFunc(targetStages, matches) {
+		p.logger.Info("ignoring workflow run not matching the workflow run filter of the target stages",
+			slog.String("workflow", e.WorkflowRun.GetName()), slog.String("event", e.WorkflowRun.GetEvent()),
+			slog.String("actor", e.WorkflowRun.GetActor().GetLogin()), slog.Any("targetStages", targetStages))
+		bus.Skip(promotion.SkipWorkflowFiltered, fmt.Sprintf("workflow %q (%s by %s) does not gate promotions into %s",
+			e.WorkflowRun.GetName(), e.WorkflowRun.GetEvent(), e.WorkflowRun.GetActor().GetLogin(), strings.Join(targetStages, ", ")))
+		return bus, nil
+	}
+
+	if len(prs) == 0 {
+		if !slices.ContainsFunc(targetStages, func(targetStage string) bool { return !matches(targetStage) }) {
+			// Every target stage is gated by the workflow run: the fast-forwarder looks the promotion request up by head SHA
+			p.logger.Info("workflow run event without matching promotion request...")
+			return bus, nil
+		}
+		// The fast-forwarder would otherwise promote into every target stage regardless of their filters
+		pCtx := *bus.Context
+		pCtx.HeadRef = helpers.NormaliseRefPtr(e.WorkflowRun.GetHeadBranch())
+		prs, err = p.githubController.FindPullRequests(ctx, &pCtx)
+		if errors.Is(err, internalGitHub.ErrNoPromotionRequest) {
+			p.logger.Info("ignoring workflow run event without matching promotion request...")
+			bus.Skip(promotion.SkipNoPromotionRequest, err.Error())
+			return bus, nil
+		}
+		if err != nil {
+			p.logger.Error("failed to find promotion PR", slog.Any("error", err))
+			return bus, err
+		}
+	}
+	prs = slices.DeleteFunc(prs, func(pr *github.PullRequest) bool { return !matches(helpers.NormaliseRef(*pr.Base.Ref)) })
+	if len(prs) == 0 {
+		p.logger.Info("ignoring workflow run event without promotion request matching its filters...")
+		bus.Skip(promotion.SkipWorkflowFiltered, fmt.Sprintf("workflow %q does not gate the open promotion requests", e.WorkflowRun.GetName()))
 		return bus, nil
 	}
+	forkPromotionRequests(bus, prs)
+
+	// Serialise the remaining processing of events targeting the same base ref
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
 
 	return bus, nil
 }
+
+// matchesWorkflowRun reports whether the workflow run matches every non-empty list of the filter.
+// Workflow patterns are matched against both the name and the path of the workflow.
+func matchesWorkflowRun(filter config.WorkflowRunFilter, run *github.WorkflowRun) bool {
+	matchesWorkflow := len(filter.Workflows) == 0 || slices.ContainsFunc(filter.Workflows, func(pattern string) bool {
+		nameMatch, _ := path.Match(pattern, run.GetName())
+		pathMatch, _ := path.Match(pattern, run.GetPath())
+		return nameMatch || pathMatch
+	})
+	matchesEvent := len(filter.Events) == 0 || slices.Contains(filter.Events, run.GetEvent())
+	matchesActor := len(filter.Actors) == 0 || slices.ContainsFunc(filter.Actors, func(actor string) bool {
+		return strings.EqualFold(actor, run.GetActor().GetLogin())
+	})
+	return matchesWorkflow && matchesEvent && matchesActor
+}
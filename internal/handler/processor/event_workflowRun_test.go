@@ -0,0 +1,174 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workflowRun returns a completed workflow run of the release workflow, triggered by a push of alice.
+func workflowRun() *github.WorkflowRun {
+	return &github.WorkflowRun{
+		Name:       new("Release"),
+		Path:       new(".github/workflows/release-eu.yml"),
+		Event:      new("push"),
+		Actor:      &github.User{Login: new("alice")},
+		Status:     new("completed"),
+		Conclusion: new("success"),
+		HeadBranch: new("main"),
+		HeadSHA:    new("c0ffee"),
+	}
+}
+
+func TestMatchesWorkflowRun(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Filter   config.WorkflowRunFilter
+		Expected bool
+	}{
+		{
+			Name:     "empty_filter",
+			Expected: true,
+		},
+		{
+			Name:     "workflow_name",
+			Filter:   config.WorkflowRunFilter{Workflows: []string{"Release"}},
+			Expected: true,
+		},
+		{
+			Name:     "workflow_name_glob",
+			Filter:   config.WorkflowRunFilter{Workflows: []string{"Rel*"}},
+			Expected: true,
+		},
+		{
+			Name:     "workflow_path_glob",
+			Filter:   config.WorkflowRunFilter{Workflows: []string{".github/workflows/release-*.yml"}},
+			Expected: true,
+		},
+		{
+			Name:     "workflow_path_glob_across_directories",
+			Filter:   config.WorkflowRunFilter{Workflows: []string{"*release-eu.yml"}},
+			Expected: false,
+		},
+		{
+			Name:     "other_workflow",
+			Filter:   config.WorkflowRunFilter{Workflows: []string{"Build", ".github/workflows/build.yml"}},
+			Expected: false,
+		},
+		{
+			Name:     "event",
+			Filter:   config.WorkflowRunFilter{Events: []string{"pull_request", "push"}},
+			Expected: true,
+		},
+		{
+			Name:     "other_event",
+			Filter:   config.WorkflowRunFilter{Events: []string{"workflow_dispatch"}},
+			Expected: false,
+		},
+		{
+			Name:     "actor_case_insensitive",
+			Filter:   config.WorkflowRunFilter{Actors: []string{"Alice"}},
+			Expected: true,
+		},
+		{
+			Name:     "other_actor",
+			Filter:   config.WorkflowRunFilter{Actors: []string{"bob"}},
+			Expected: false,
+		},
+		{
+			Name: "every_list",
+			Filter: config.WorkflowRunFilter{
+				Workflows: []string{"Release"},
+				Events:    []string{"push"},
+				Actors:    []string{"alice"},
+			},
+			Expected: true,
+		},
+		{
+			Name: "one_list_unmatched",
+			Filter: config.WorkflowRunFilter{
+				Workflows: []string{"Release"},
+				Events:    []string{"push"},
+				Actors:    []string{"bob"},
+			},
+			Expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expected, processor.MatchesWorkflowRun(tc.Filter, workflowRun()))
+		})
+	}
+}
+
+func TestWorkflowRunEventProcessor(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		Filter             config.WorkflowRunFilter
+		PullRequests       []*github.PullRequest
+		ExpectedSkipReason promotion.SkipReason
+		ExpectedBaseRef    string
+	}{
+		{
+			// The fast-forwarder looks the promotion request up by head SHA
+			Name: "matching_workflow_without_promotion_request",
+		},
+		{
+			Name:            "matching_workflow_with_promotion_request",
+			Filter:          config.WorkflowRunFilter{Workflows: []string{"Release"}},
+			PullRequests:    []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")},
+			ExpectedBaseRef: "staging",
+		},
+		{
+			Name:               "filtered_workflow",
+			Filter:             config.WorkflowRunFilter{Workflows: []string{"Build"}},
+			ExpectedSkipReason: promotion.SkipWorkflowFiltered,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.WorkflowRun = tc.Filter })
+			run := workflowRun()
+			run.PullRequests = tc.PullRequests
+			bus := newFakeGitHub(t).bus(t, event.WorkflowRun, &github.WorkflowRunEvent{WorkflowRun: run}, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewWorkflowRunEventProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.Equal(t, tc.ExpectedBaseRef, helpers.String(bus.Context.BaseRef))
+			assert.Equal(t, "c0ffee", helpers.String(bus.Context.HeadSHA))
+		})
+	}
+}
+
+func TestWorkflowRunEventProcessorFansOut(t *testing.T) {
+	setStage(t, "canary-eu", func(stage *config.Stage) { stage.WorkflowRun.Workflows = []string{"Release"} })
+	setStage(t, "canary-us", func(stage *config.Stage) { stage.WorkflowRun.Workflows = []string{"Build"} })
+	fake := newFakeGitHub(t)
+	fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{
+		pullRequest(1, "main", "canary-eu", "c0ffee"),
+		pullRequest(2, "main", "canary-us", "c0ffee"),
+	})
+	bus := fake.bus(t, event.WorkflowRun, &github.WorkflowRunEvent{WorkflowRun: workflowRun()})
+	promoter, err := promotion.NewGraphPromoter("test", "main > canary-eu, canary-us")
+	require.NoError(t, err)
+	bus.Context.Promoter = promoter
+
+	bus, err = processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewWorkflowRunEventProcessor(newController(t)))
+	require.NoError(t, err)
+	// Only the target stages gated by the workflow are promoted into
+	assert.Empty(t, skipReason(bus))
+	assert.Equal(t, 1, bus.Context.PullRequest.GetNumber())
+	assert.Empty(t, bus.Forks)
+}
@@ -0,0 +1,9 @@
+package processor
+
+// Exported for testing.
+var (
+	ReconcileStagePairs   = reconcileStagePairs
+	ReconcileRepositories = reconcileRepositories
+	MatchesWorkflowRun    = matchesWorkflowRun
+	ContextPattern        = contextPattern
+)
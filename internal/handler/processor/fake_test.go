@@ -0,0 +1,216 @@
+package processor_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOwner      = "owner"
+	testRepository = "repo"
+)
+
+func TestMain(m *testing.M) {
+	if err := config.SetDefaults(); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// restoreConfig restores the promotion configuration once the test completes.
+func restoreConfig(t *testing.T) {
+	t.Helper()
+	saved := config.Promotion
+	t.Cleanup(func() { config.Promotion = saved })
+}
+
+// setStage overrides the settings of promotions into the stage for the duration of the test, on top of the defaults.
+func setStage(t *testing.T, name string, override func(stage *config.Stage)) {
+	t.Helper()
+	restoreConfig(t)
+	stage := config.Promotion.Stage(name)
+	override(&stage)
+	config.Promotion.Stages = maps.Clone(config.Promotion.Stages)
+	if config.Promotion.Stages == nil {
+		config.Promotion.Stages = make(map[string]config.Stage)
+	}
+	config.Promotion.Stages[name] = stage
+}
+
+// fakeRequest is a request received by the fake GitHub API.
+type fakeRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+type fakeRoute struct {
+	status int
+	body   any
+}
+
+// fakeGitHub stands in for the GitHub REST and GraphQL APIs. Routes answer with canned JSON documents, and unrouted
+// requests are answered with 404 Not Found, as GitHub answers requests for missing resources.
+type fakeGitHub struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	routes   map[string]fakeRoute
+	requests []fakeRequest
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+	f := &fakeGitHub{routes: make(map[string]fakeRoute)}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGitHub) serve(rw http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	f.mu.Lock()
+	f.requests = append(f.requests, fakeRequest{Method: req.Method, Path: req.URL.Path, Body: string(body)})
+	route, found := f.routes[req.Method+" "+req.URL.Path]
+	f.mu.Unlock()
+
+	rw.Header().Set("Content-Type", "application/json")
+	if !found {
+		rw.WriteHeader(http.StatusNotFound)
+		_, _ = rw.Write([]byte(`{"message": "Not Found"}`))
+		return
+	}
+	rw.WriteHeader(route.status)
+	if route.body != nil {
+		_ = json.NewEncoder(rw).Encode(route.body)
+	}
+}
+
+// on answers the requests with the given method and path, e.g. "GET", "/repos/owner/repo/pulls", with the given status
+// and JSON document.
+func (f *fakeGitHub) on(method, path string, status int, body any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.routes[method+" "+path] = fakeRoute{status: status, body: body}
+}
+
+// onRef answers the lookups of the branch of the test repository with the given head SHA.
+func (f *fakeGitHub) onRef(branch, sha string) {
+	f.on(http.MethodGet, "/repos/owner/repo/git/ref/heads/"+branch, http.StatusOK, github.Reference{
+		Ref:    new("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: &sha},
+	})
+}
+
+// onCompare answers the comparisons of the base and head of the test repository with the given status and number of
+// commits the head is ahead by.
+func (f *fakeGitHub) onCompare(base, head, status string, aheadBy int) {
+	f.on(http.MethodGet, "/repos/owner/repo/compare/"+base+"..."+head, http.StatusOK, github.CommitsComparison{
+		Status:  &status,
+		AheadBy: &aheadBy,
+	})
+}
+
+// received returns the requests received with the given method and path.
+func (f *fakeGitHub) received(method, path string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var received []fakeRequest
+	for _, req := range f.requests {
+		if req.Method == method && req.Path == path {
+			received = append(received, req)
+		}
+	}
+	return received
+}
+
+// receivedPrefix returns the requests received with the given method and a path starting with the given prefix.
+func (f *fakeGitHub) receivedPrefix(method, prefix string) []fakeRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var received []fakeRequest
+	for _, req := range f.requests {
+		if req.Method == method && strings.HasPrefix(req.Path, prefix) {
+			received = append(received, req)
+		}
+	}
+	return received
+}
+
+// clients returns the GitHub clients of the fake API.
+func (f *fakeGitHub) clients(t *testing.T) *internalGitHub.Client {
+	t.Helper()
+	clientV3, err := github.NewClient(github.WithURLs(new(f.server.URL+"/"), new(f.server.URL+"/uploads/")))
+	require.NoError(t, err)
+	return &internalGitHub.Client{
+		V3: clientV3,
+		V4: githubv4.NewEnterpriseClient(f.server.URL+"/graphql", f.server.Client()),
+	}
+}
+
+// newController returns a controller authenticated with a personal access token.
+func newController(t *testing.T) *internalGitHub.Controller {
+	t.Helper()
+	controller, err := internalGitHub.NewController(internalGitHub.WithAuthMode("token"), internalGitHub.WithToken("token"))
+	require.NoError(t, err)
+	return controller
+}
+
+// bus returns the bus of an event of the test repository, promoted along the given stages.
+func (f *fakeGitHub) bus(t *testing.T, eventType event.Type, evt any, stages ...string) *promotion.Bus {
+	t.Helper()
+	clients := f.clients(t)
+	return &promotion.Bus{
+		EventType:   eventType,
+		Event:       evt,
+		EventStatus: promotion.Error,
+		Repository:  &models.RepositoryContext{},
+		Context: &promotion.Context{
+			EventType:  evt,
+			Owner:      new(testOwner),
+			Repository: new(testRepository),
+			ClientV3:   clients.V3,
+			ClientV4:   clients.V4,
+			Promoter:   promotion.NewStagePromoter("test", stages),
+			Logger:     helpers.NewNoopLogger(),
+		},
+	}
+}
+
+// pullRequest returns an open promotion request of the test repository.
+func pullRequest(number int, head, base, sha string) *github.PullRequest {
+	return &github.PullRequest{
+		Number: &number,
+		URL:    new(fmt.Sprintf("https://api.github.com/repos/owner/repo/pulls/%d", number)),
+		State:  new("open"),
+		Labels: []*github.Label{},
+		Head:   &github.PullRequestBranch{Ref: &head, SHA: &sha},
+		Base:   &github.PullRequestBranch{Ref: &base},
+	}
+}
+
+// skipReason returns the reason the last processor recorded for skipping the event, if any.
+func skipReason(bus *promotion.Bus) promotion.SkipReason {
+	if len(bus.Trace) == 0 {
+		return ""
+	}
+	return promotion.SkipReason(bus.Trace[len(bus.Trace)-1].Reason)
+}
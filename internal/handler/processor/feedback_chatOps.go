@@ -0,0 +1,90 @@
+package processor
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type chatOpsFeedbackProcessor struct {
+	logger           *slog.Logger
+	githubController *github.Controller
+}
+
+// NewChatOpsFeedbackProcessor creates a new processor replying to ChatOps commands with their outcome and decision trace.
+func NewChatOpsFeedbackProcessor(githubController *github.Controller, opts ...Option) Processor {
+	_inst := &chatOpsFeedbackProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (c *chatOpsFeedbackProcessor) Name() string {
+	return "chat-ops"
+}
+
+func (c *chatOpsFeedbackProcessor) SetLogger(logger *slog.Logger) {
+	c.logger = logger.WithGroup("feedback-processor:chat-ops")
+}
+
+func (c *chatOpsFeedbackProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
+	c.logger.Debug("processing chat-ops feedback...")
+	bus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+
+	if bus.Command == nil {
+		return bus, nil
+	}
+	if bus.Command.Name == promotion.CommandExplain && !config.Global.DryRun {
+		// Only the evaluation is planned, the reply explaining it is posted
+		bus.Response.Plan = bus.Plan.Actions()
+		ctx = promotion.ContextWithPlan(ctx, nil)
+	}
+
+	if replyErr := c.githubController.CommentOnPullRequest(ctx, bus.Context, bus.Command.Number, c.reply(bus)); replyErr != nil {
+		c.logger.Error("failed to reply to command", slog.Any("error", replyErr))
+	}
+	return bus, nil
+}
+
+// reply renders the outcome of the command as a markdown comment.
+func (c *chatOpsFeedbackProcessor) reply(bus *promotion.Bus) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "> /%s\n\n@%s: **%s**", bus.Command.Name, bus.Command.Actor, bus.EventStatus)
+	if bus.Response.Body != "" {
+		fmt.Fprintf(&b, " — %s", bus.Response.Body)
+	}
+	b.WriteString("\n")
+
+	if bus.Command.Name != promotion.CommandExplain {
+		// Explain why the promotion did not go through
+		for _, step := range slices.Backward(bus.Trace) {
+			if step.Outcome != promotion.OutcomeProcessed {
+				fmt.Fprintf(&b, "\n`%s` %s: %s\n", step.Processor, step.Outcome, cmp.Or(step.Message, step.Error, step.Reason))
+				break
+			}
+		}
+	} else {
+		b.WriteString("\n| Processor | Outcome | Reason | Message |\n|---|---|---|---|\n")
+		for _, step := range bus.Trace {
+			message := cmp.Or(step.Message, step.Error)
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", step.Processor, step.Outcome, step.Reason, strings.ReplaceAll(message, "|", `\|`))
+		}
+		if actions := bus.Plan.Actions(); len(actions) > 0 {
+			b.WriteString("\nPlanned actions:\n")
+			for _, action := range actions {
+				fmt.Fprintf(&b, "- `%s` %s %s\n", action.Action, action.Ref, action.SHA)
+			}
+		}
+	}
+	return b.String()
+}
@@ -1,6 +1,8 @@
 package processor //nolint:dupl // Processor implementations are similar
 
 import (
+	"cmp"
+	"context"
 	"log/slog"
 
 	"github.com/isometry/gh-promotion-app/internal/config"
@@ -12,6 +14,12 @@ import (
 type checkRunFeedbackProcessor struct {
 	logger           *slog.Logger
 	githubController *github.Controller
+	options          checkRunFeedbackOptions
+}
+
+type checkRunFeedbackOptions struct {
+	// Name overrides the globally configured check-run name. Supported placeholders: {source}, {target}, {progress}
+	Name string `yaml:"name,omitempty"`
 }
 
 // NewCheckRunFeedbackProcessor creates a new processor for handling feedback from check run events.
@@ -21,11 +29,19 @@ func NewCheckRunFeedbackProcessor(githubController *github.Controller, opts ...O
 	return _inst
 }
 
+func (c *checkRunFeedbackProcessor) Name() string {
+	return "check-run-feedback"
+}
+
 func (c *checkRunFeedbackProcessor) SetLogger(logger *slog.Logger) {
 	c.logger = logger.WithGroup("feedback-processor:check-run")
 }
 
-func (c *checkRunFeedbackProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (c *checkRunFeedbackProcessor) Configure(options map[string]any) error {
+	return decodeOptions(options, &c.options)
+}
+
+func (c *checkRunFeedbackProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	c.logger.Debug("processing check-run feedback...")
 	bus, ok := req.(*promotion.Bus)
 	if !ok {
@@ -47,7 +63,7 @@ func (c *checkRunFeedbackProcessor) Process(req any) (bus *promotion.Bus, err er
 		conclusion = github.CheckRunConclusionSuccess
 	}
 
-	if statusErr := c.githubController.SendPromotionFeedbackCheckRun(bus, conclusion); statusErr != nil {
+	if statusErr := c.githubController.SendPromotionFeedbackCheckRun(ctx, bus, conclusion, cmp.Or(c.options.Name, config.Promotion.Feedback.CheckRun.Name)); statusErr != nil {
 		c.logger.Error("failed to send feedback check-run", slog.Any("error", statusErr))
 	}
 	return
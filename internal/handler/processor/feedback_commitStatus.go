@@ -1,6 +1,8 @@
 package processor //nolint:dupl // Processor implementations are similar
 
 import (
+	"cmp"
+	"context"
 	"log/slog"
 
 	"github.com/isometry/gh-promotion-app/internal/config"
@@ -12,6 +14,12 @@ import (
 type commitStatusFeedbackProcessor struct {
 	logger           *slog.Logger
 	githubController *github.Controller
+	options          commitStatusFeedbackOptions
+}
+
+type commitStatusFeedbackOptions struct {
+	// Context overrides the globally configured commit status context. Supported placeholders: {source}, {target}, {progress}
+	Context string `yaml:"context,omitempty"`
 }
 
 // NewCommitStatusFeedbackProcessor creates a new processor for handling feedback from commit status checks.
@@ -21,11 +29,19 @@ func NewCommitStatusFeedbackProcessor(githubController *github.Controller, opts
 	return _inst
 }
 
+func (p *commitStatusFeedbackProcessor) Name() string {
+	return "commit-status"
+}
+
 func (p *commitStatusFeedbackProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("feedback-processor:commit-status")
 }
 
-func (p *commitStatusFeedbackProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *commitStatusFeedbackProcessor) Configure(options map[string]any) error {
+	return decodeOptions(options, &p.options)
+}
+
+func (p *commitStatusFeedbackProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	p.logger.Debug("processing commit-status feedback...")
 	bus, ok := req.(*promotion.Bus)
 	if !ok {
@@ -60,7 +76,7 @@ func (p *commitStatusFeedbackProcessor) Process(req any) (bus *promotion.Bus, er
 		}
 	}
 
-	if statusErr := p.githubController.SendPromotionFeedbackCommitStatus(bus, status); statusErr != nil {
+	if statusErr := p.githubController.SendPromotionFeedbackCommitStatus(ctx, bus, status, cmp.Or(p.options.Context, config.Promotion.Feedback.CommitStatus.Context)); statusErr != nil {
 		p.logger.Error("failed to send feedback commit-status", slog.Any("error", statusErr))
 	}
 
@@ -1,10 +1,16 @@
 package processor
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strings"
 
+	"github.com/isometry/gh-promotion-app/internal/config"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
 	"github.com/isometry/gh-promotion-app/internal/models"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
@@ -22,11 +28,15 @@ func NewFastForwarderPostProcessor(githubController *github.Controller, opts ...
 	return _inst
 }
 
+func (p *fastForwarderPostProcessor) Name() string {
+	return "fast-forwarder"
+}
+
 func (p *fastForwarderPostProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("post-processor:fast-forwarder")
 }
 
-func (p *fastForwarderPostProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *fastForwarderPostProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	parsedBus, ok := req.(*promotion.Bus)
 	if !ok {
 		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
@@ -35,25 +45,37 @@ func (p *fastForwarderPostProcessor) Process(req any) (bus *promotion.Bus, err e
 
 	p.logger.Debug("processing fast-forwarder...")
 
+	if bus.Resolved {
+		p.logger.Debug("ignoring event resolved by its event processor")
+		return bus, nil
+	}
+
 	if bus.Context.HeadSHA == nil {
 		p.logger.Debug("ignoring event without a head SHA")
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipMissingHeadSHA, "event does not resolve to a head SHA")
 		return bus, nil
 	}
 
 	if bus.Context.BaseRef == nil || bus.Context.HeadRef == nil {
 		// ignore events without an open promotion PR
-		if bus.Context.PullRequest, err = p.githubController.FindPullRequest(bus.Context); err != nil {
+		prs, err := p.githubController.FindPullRequests(ctx, bus.Context)
+		if errors.Is(err, github.ErrNoPromotionRequest) {
+			p.logger.Info("ignoring event without an open promotion PR")
+			bus.Skip(promotion.SkipNoPromotionRequest, err.Error())
+			return bus, nil
+		}
+		if err != nil {
 			p.logger.Error("failed to find promotion PR", slog.Any("error", err))
-			bus.EventStatus = promotion.Skipped
 			return bus, err
 		}
-		p.logger.Debug("found promotion PR", slog.String("headRef", *bus.Context.HeadRef))
+		// Head SHAs fanning out of a stage are promoted into each target stage, all but the first with a fork of the bus
+		forkPromotionRequests(bus, prs)
+		p.logger.Debug("found promotion PR", slog.String("headRef", *bus.Context.HeadRef), slog.Int("forks", len(bus.Forks)))
 	}
 
 	// @Note: deactivated to cope with API limits
 	// if bus.Context.Commits == nil {
-	//	if bus.Context.Commits, err = p.githubController.ListPullRequestCommits(bus.Context); err != nil {
+	//	if bus.Context.Commits, err = p.githubController.ListPullRequestCommits(ctx, bus.Context); err != nil {
 	//		p.logger.Error("failed to find commits", slog.Any("error", err))
 	//		bus.EventStatus = promotion.Skipped
 	//		return bus, err
@@ -65,19 +87,170 @@ func (p *fastForwarderPostProcessor) Process(req any) (bus *promotion.Bus, err e
 	if !isPromotable {
 		p.logger.Debug("ignoring event on a non-promotion branch",
 			slog.String("headRef", *bus.Context.HeadRef))
-		bus.EventStatus = promotion.Skipped
+		bus.Skip(promotion.SkipNotPromotionBranch, fmt.Sprintf("%s is not a promotion source", *bus.Context.HeadRef))
+		return bus, nil
+	}
+
+	// Serialise concurrent events for the same base ref, then re-check the target ref now that the lock is held:
+	// a concurrent event for the same SHA may already have completed the promotion
+	if err = bus.LockBaseRef(ctx); err != nil {
+		return bus, err
+	}
+	sha, err := p.githubController.GetPromotionTargetRefSHA(ctx, bus.Context)
+	if err != nil && promotion.Classify(err) != promotion.KindInvalidInput {
+		p.logger.Error("failed to get target ref", slog.Any("error", err))
+		return bus, err
+	}
+	// A missing target ref is not promoted yet: the fast-forward reports it
+	if err == nil && sha == *bus.Context.HeadSHA {
+		p.logger.Info("ignoring event on an already promoted SHA", slog.String("baseRef", *bus.Context.BaseRef))
+		bus.Response = models.Response{Body: "Already promoted", StatusCode: http.StatusOK}
+		bus.Skip(promotion.SkipAlreadyPromoted, fmt.Sprintf("%s already points to %s", *bus.Context.BaseRef, sha))
+		return bus, nil
+	}
+
+	held, err := p.githubController.IsOnHold(ctx, bus.Context)
+	if err != nil {
+		p.logger.Error("failed to check promotion hold", slog.Any("error", err))
+		return bus, err
+	}
+	if held {
+		p.logger.Info("ignoring event on a held promotion request")
+		bus.Skip(promotion.SkipOnHold, fmt.Sprintf("promotion request is labelled %q", config.Promotion.ChatOps.HoldLabel))
+		return bus, nil
+	}
+
+	// Promotions into join stages await the head SHA in every other stage promoting into them
+	awaited, err := p.awaitedStages(ctx, bus)
+	if err != nil {
+		p.logger.Error("failed to check the previous stages of the join stage", slog.Any("error", err))
+		return bus, err
+	}
+	if len(awaited) > 0 {
+		p.logger.Info("ignoring event on a SHA not yet promoted into every previous stage of the join stage", slog.Any("awaited", awaited))
+		bus.Response = models.Response{Body: fmt.Sprintf("Promotion awaiting %s", strings.Join(awaited, ", ")), StatusCode: http.StatusOK}
+		bus.Skip(promotion.SkipAwaitingParents, fmt.Sprintf("%s is not yet promoted into %s", *bus.Context.HeadSHA, strings.Join(awaited, ", ")))
 		return bus, nil
 	}
 
-	if err = p.githubController.FastForwardRefToSha(bus.Context); err != nil {
+	// Promotions into stages gated by a deployment environment await a successful deployment of the head SHA,
+	// already checked by the deployment status event processor
+	if environment := config.Promotion.Stage(helpers.NormaliseRef(*bus.Context.BaseRef)).DeploymentEnvironment; environment != "" {
+		if bus.EventType != event.DeploymentStatus {
+			deployed, err := p.githubController.HasSuccessfulDeployment(ctx, bus.Context, environment)
+			if err != nil {
+				p.logger.Error("failed to check deployment", slog.Any("error", err))
+				return bus, err
+			}
+			if !deployed {
+				p.logger.Info("ignoring event on a SHA not yet deployed", slog.String("environment", environment))
+				bus.Skip(promotion.SkipNotDeployed, fmt.Sprintf("%s is not successfully deployed to %s", *bus.Context.HeadSHA, environment))
+				return bus, nil
+			}
+		}
+	}
+
+	switch strategy := config.Promotion.Stage(helpers.NormaliseRef(*bus.Context.BaseRef)).Strategy; strategy {
+	case config.StrategyFastForward:
+	case config.StrategyMergeQueue:
+		return p.enqueue(ctx, bus)
+	default:
+		return bus, promotion.NewInternalErrorf("unsupported promotion strategy %q for stage %s", strategy, *bus.Context.BaseRef)
+	}
+
+	readiness, err := p.evaluateReadiness(ctx, bus)
+	if err != nil {
+		p.logger.Error("failed to evaluate readiness", slog.Any("error", err))
+		return bus, err
+	}
+	if !readiness.Ready() {
+		p.logger.Info("ignoring event on a SHA with unsuccessful required checks", slog.String("readiness", readiness.String()))
+		bus.Response = models.Response{Body: fmt.Sprintf("Promotion not ready: %s", readiness), StatusCode: http.StatusOK}
+		bus.Skip(promotion.SkipNotReady, readiness.String())
+		return bus, nil
+	}
+
+	if err = p.githubController.FastForwardRefToSha(ctx, bus.Context); err != nil {
 		p.logger.Error("failed to fast-forward ref", slog.Any("error", err))
-		bus.Response = models.Response{Body: err.Error(), StatusCode: http.StatusInternalServerError}
+		bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
 		bus.Error = err
 		return bus, err
 	}
 
 	p.logger.Info("fast-forward complete")
-	bus.Response = models.Response{Body: "Promotion complete", StatusCode: http.StatusNoContent}
+	// 204 responses cannot carry the decision trace
+	bus.Response = models.Response{Body: "Promotion complete", StatusCode: http.StatusOK}
 	bus.EventStatus = promotion.Success
 	return bus, nil
 }
+
+// awaitedStages returns the other stages promoting into the base ref that do not contain the head SHA yet. It is empty
+// unless the base ref is a join stage.
+func (p *fastForwarderPostProcessor) awaitedStages(ctx context.Context, bus *promotion.Bus) ([]string, error) {
+	previousStages := bus.Context.Promoter.PreviousStages(*bus.Context.BaseRef)
+	if len(previousStages) < 2 {
+		return nil, nil
+	}
+	var awaited []string
+	for _, previousStage := range previousStages {
+		if previousStage == helpers.NormaliseRef(bus.Context.HeadRef) {
+			continue
+		}
+		contained, err := p.githubController.StageContainsSHA(ctx, bus.Context, previousStage, *bus.Context.HeadSHA)
+		if err != nil {
+			return nil, err
+		}
+		if !contained {
+			awaited = append(awaited, previousStage)
+		}
+	}
+	return awaited, nil
+}
+
+// evaluateReadiness evaluates the checks of the head SHA against the checks required by the target stage,
+// either configured or inherited from its branch protection and rulesets.
+func (p *fastForwarderPostProcessor) evaluateReadiness(ctx context.Context, bus *promotion.Bus) (promotion.Readiness, error) {
+	required := config.Promotion.Stage(helpers.NormaliseRef(*bus.Context.BaseRef)).RequiredChecks
+	if required == nil {
+		var err error
+		if required, err = p.githubController.GetRequiredChecks(ctx, bus.Context); err != nil {
+			return promotion.Readiness{}, err
+		}
+	}
+	if len(required) == 0 {
+		return promotion.Readiness{}, nil
+	}
+
+	statuses, checkRuns, err := p.githubController.ListCommitChecks(ctx, bus.Context)
+	if err != nil {
+		return promotion.Readiness{}, err
+	}
+	return promotion.EvaluateReadiness(required, statuses, checkRuns), nil
+}
+
+// enqueue adds the promotion request to the merge queue of the stage; the merge_group events report the outcome.
+func (p *fastForwarderPostProcessor) enqueue(ctx context.Context, bus *promotion.Bus) (_ *promotion.Bus, err error) {
+	if bus.Context.PullRequest == nil {
+		bus.Context.PullRequest, err = p.githubController.FindPullRequest(ctx, bus.Context)
+		if errors.Is(err, github.ErrNoPromotionRequest) {
+			p.logger.Info("ignoring event without an open promotion PR")
+			bus.Skip(promotion.SkipNoPromotionRequest, err.Error())
+			return bus, nil
+		}
+		if err != nil {
+			p.logger.Error("failed to find promotion PR", slog.Any("error", err))
+			return bus, err
+		}
+	}
+	if err = p.githubController.EnqueuePullRequest(ctx, bus.Context); err != nil {
+		p.logger.Error("failed to enqueue promotion request", slog.Any("error", err))
+		bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
+		bus.Error = err
+		return bus, err
+	}
+
+	p.logger.Info("promotion request enqueued")
+	bus.Response = models.Response{Body: "Promotion enqueued", StatusCode: http.StatusAccepted}
+	bus.EventStatus = promotion.Pending
+	return bus, nil
+}
@@ -0,0 +1,425 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fastForwardPath = "/repos/owner/repo/git/refs/heads/staging"
+
+// newPromotionBus returns the bus of a push of the head SHA to main, with its promotion request into staging.
+func newPromotionBus(t *testing.T, fake *fakeGitHub, pr *github.PullRequest) *promotion.Bus {
+	t.Helper()
+	bus := fake.bus(t, event.Push, &github.PushEvent{}, "main", "staging", "production")
+	bus.Context.HeadSHA = new("c0ffee")
+	bus.Context.HeadRef = new("main")
+	bus.Context.BaseRef = new("staging")
+	bus.Context.PullRequest = pr
+	return bus
+}
+
+func TestFastForwarderPostProcessor(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		Setup               func(fake *fakeGitHub)
+		PullRequest         *github.PullRequest
+		ExpectedStatus      promotion.EventStatus
+		ExpectedStatusCode  int
+		ExpectedFastForward bool
+		ExpectedError       bool
+	}{
+		{
+			Name: "promoted",
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+			},
+			PullRequest:         pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus:      promotion.Success,
+			ExpectedStatusCode:  http.StatusOK,
+			ExpectedFastForward: true,
+		},
+		{
+			Name: "already_promoted",
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("staging", "c0ffee")
+			},
+			PullRequest:        pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus:     promotion.Skipped,
+			ExpectedStatusCode: http.StatusOK,
+		},
+		{
+			Name: "target_ref_lookup_failure",
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, "/repos/owner/repo/git/ref/heads/staging", http.StatusBadGateway, map[string]string{"message": "Bad Gateway"})
+				fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+			},
+			PullRequest:    pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus: promotion.Error,
+			ExpectedError:  true,
+		},
+		{
+			Name:  "on_hold",
+			Setup: func(*fakeGitHub) {},
+			PullRequest: func() *github.PullRequest {
+				pr := pullRequest(1, "main", "staging", "c0ffee")
+				pr.Labels = []*github.Label{{Name: new("promotion-hold")}}
+				return pr
+			}(),
+			ExpectedStatus: promotion.Skipped,
+		},
+		{
+			Name: "not_fast_forwardable",
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodPatch, fastForwardPath, http.StatusUnprocessableEntity, map[string]string{"message": "Update is not a fast forward"})
+			},
+			PullRequest:         pullRequest(1, "main", "staging", "c0ffee"),
+			ExpectedStatus:      promotion.Error,
+			ExpectedStatusCode:  http.StatusConflict,
+			ExpectedFastForward: true,
+			ExpectedError:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			tc.Setup(fake)
+			bus := newPromotionBus(t, fake, tc.PullRequest)
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+			if tc.ExpectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.ExpectedStatus, bus.EventStatus)
+			if tc.ExpectedStatusCode != 0 {
+				assert.Equal(t, tc.ExpectedStatusCode, bus.Response.StatusCode)
+			}
+			assert.Equal(t, tc.ExpectedFastForward, len(fake.received(http.MethodPatch, fastForwardPath)) > 0)
+		})
+	}
+}
+
+func TestFastForwarderPostProcessorRespondsWithTrace(t *testing.T) {
+	fake := newFakeGitHub(t)
+	fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+	bus := newPromotionBus(t, fake, pullRequest(1, "main", "staging", "c0ffee"))
+
+	bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+	require.NoError(t, err)
+	require.Equal(t, promotion.Success, bus.EventStatus)
+
+	bus.Response.Trace = bus.Trace
+	rw := httptest.NewRecorder()
+	helpers.RespondHTTP(rw, bus.Response, nil)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, rw.Body.String(), `"processor":"fast-forwarder"`)
+	assert.Contains(t, fake.received(http.MethodPatch, fastForwardPath)[0].Body, `"sha":"c0ffee"`)
+}
+
+func TestFastForwarderPostProcessorLooksUpPromotionRequest(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		Status            int
+		PullRequests      []*github.PullRequest
+		ExpectedStatus    promotion.EventStatus
+		ExpectedErrorKind promotion.ErrorKind
+	}{
+		{
+			Name:           "no_promotion_request",
+			Status:         http.StatusOK,
+			PullRequests:   []*github.PullRequest{pullRequest(1, "main", "staging", "deadbeef")},
+			ExpectedStatus: promotion.Skipped,
+		},
+		{
+			Name:              "lookup_failure",
+			Status:            http.StatusBadGateway,
+			ExpectedStatus:    promotion.Error,
+			ExpectedErrorKind: promotion.KindTransient,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls", tc.Status, tc.PullRequests)
+			// Status events only carry the head SHA
+			bus := fake.bus(t, event.Status, &github.StatusEvent{}, "main", "staging", "production")
+			bus.Context.HeadSHA = new("c0ffee")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+			if tc.ExpectedErrorKind == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, tc.ExpectedErrorKind, promotion.Classify(err))
+			}
+			assert.Equal(t, tc.ExpectedStatus, bus.EventStatus)
+			assert.Empty(t, fake.received(http.MethodPatch, fastForwardPath))
+		})
+	}
+}
+
+func TestFastForwarderPostProcessorEnqueues(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		NodeID             string
+		GraphQLResponse    map[string]any
+		ExpectedStatus     promotion.EventStatus
+		ExpectedStatusCode int
+		ExpectedLookup     bool
+		ExpectedError      bool
+	}{
+		{
+			Name:               "enqueued",
+			NodeID:             "PR_1",
+			ExpectedStatus:     promotion.Pending,
+			ExpectedStatusCode: http.StatusAccepted,
+		},
+		{
+			// Pull requests embedded in check suite and check run payloads lack their node ID
+			Name:               "enqueued_without_node_id",
+			ExpectedStatus:     promotion.Pending,
+			ExpectedStatusCode: http.StatusAccepted,
+			ExpectedLookup:     true,
+		},
+		{
+			Name:               "enqueue_rejected",
+			NodeID:             "PR_1",
+			GraphQLResponse:    map[string]any{"errors": []map[string]string{{"message": "Pull request is not mergeable"}}},
+			ExpectedStatus:     promotion.Error,
+			ExpectedStatusCode: http.StatusInternalServerError,
+			ExpectedError:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.Strategy = config.StrategyMergeQueue })
+			fake := newFakeGitHub(t)
+			pr := pullRequest(1, "main", "staging", "c0ffee")
+			fetched := pullRequest(1, "main", "staging", "c0ffee")
+			fetched.NodeID = new("PR_1")
+			if tc.NodeID != "" {
+				pr.NodeID = &tc.NodeID
+			}
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls/1", http.StatusOK, fetched)
+			if tc.GraphQLResponse == nil {
+				tc.GraphQLResponse = map[string]any{
+					"data": map[string]any{"enqueuePullRequest": map[string]any{"mergeQueueEntry": map[string]any{"position": 1, "state": "QUEUED"}}},
+				}
+			}
+			fake.on(http.MethodPost, "/graphql", http.StatusOK, tc.GraphQLResponse)
+			bus := newPromotionBus(t, fake, pr)
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+			if tc.ExpectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tc.ExpectedStatus, bus.EventStatus)
+			assert.Equal(t, tc.ExpectedStatusCode, bus.Response.StatusCode)
+			assert.Equal(t, tc.ExpectedLookup, len(fake.received(http.MethodGet, "/repos/owner/repo/pulls/1")) > 0)
+			enqueued := fake.received(http.MethodPost, "/graphql")
+			require.Len(t, enqueued, 1)
+			assert.Contains(t, enqueued[0].Body, `"pullRequestId":"PR_1"`)
+			assert.Contains(t, enqueued[0].Body, `"expectedHeadOid":"c0ffee"`)
+			// The merge queue applies the promotion
+			assert.Empty(t, fake.received(http.MethodPatch, fastForwardPath))
+		})
+	}
+}
+
+func TestFastForwarderPostProcessorAwaitsDeployment(t *testing.T) {
+	testCases := []struct {
+		Name                string
+		Deployments         []*github.Deployment
+		DeploymentsStatus   int
+		State               string
+		ExpectedStatus      promotion.EventStatus
+		ExpectedSkipReason  promotion.SkipReason
+		ExpectedFastForward bool
+		ExpectedErrorKind   promotion.ErrorKind
+	}{
+		{
+			Name:                "deployed",
+			Deployments:         []*github.Deployment{{ID: new(int64(7))}},
+			DeploymentsStatus:   http.StatusOK,
+			State:               "success",
+			ExpectedStatus:      promotion.Success,
+			ExpectedFastForward: true,
+		},
+		{
+			Name:               "not_deployed",
+			Deployments:        []*github.Deployment{},
+			DeploymentsStatus:  http.StatusOK,
+			ExpectedStatus:     promotion.Skipped,
+			ExpectedSkipReason: promotion.SkipNotDeployed,
+		},
+		{
+			Name:               "failed_deployment",
+			Deployments:        []*github.Deployment{{ID: new(int64(7))}},
+			DeploymentsStatus:  http.StatusOK,
+			State:              "failure",
+			ExpectedStatus:     promotion.Skipped,
+			ExpectedSkipReason: promotion.SkipNotDeployed,
+		},
+		{
+			Name:              "lookup_failure",
+			DeploymentsStatus: http.StatusBadGateway,
+			ExpectedStatus:    promotion.Error,
+			ExpectedErrorKind: promotion.KindTransient,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.DeploymentEnvironment = "dev" })
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/deployments", tc.DeploymentsStatus, tc.Deployments)
+			fake.on(http.MethodGet, "/repos/owner/repo/deployments/7/statuses", http.StatusOK, []*github.DeploymentStatus{{State: &tc.State}})
+			fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+			bus := newPromotionBus(t, fake, pullRequest(1, "main", "staging", "c0ffee"))
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+			if tc.ExpectedErrorKind == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Equal(t, tc.ExpectedErrorKind, promotion.Classify(err))
+			}
+			assert.Equal(t, tc.ExpectedStatus, bus.EventStatus)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.Equal(t, tc.ExpectedFastForward, len(fake.received(http.MethodPatch, fastForwardPath)) > 0)
+		})
+	}
+}
+
+func TestFastForwarderPostProcessorEvaluatesReadiness(t *testing.T) {
+	const (
+		protectionPath = "/repos/owner/repo/branches/staging/protection/required_status_checks"
+		rulesPath      = "/repos/owner/repo/rules/branches/staging"
+		statusPath     = "/repos/owner/repo/commits/c0ffee/status"
+		checkRunsPath  = "/repos/owner/repo/commits/c0ffee/check-runs"
+	)
+	status := func(context, state string) *github.RepoStatus {
+		return &github.RepoStatus{Context: &context, State: &state}
+	}
+	checkRun := func(name, runStatus, conclusion string) *github.CheckRun {
+		return &github.CheckRun{Name: &name, Status: &runStatus, Conclusion: &conclusion}
+	}
+
+	testCases := []struct {
+		Name                string
+		RequiredChecks      []string
+		Setup               func(fake *fakeGitHub)
+		ExpectedSkipReason  promotion.SkipReason
+		ExpectedBody        string
+		ExpectedFastForward bool
+	}{
+		{
+			Name:           "configured_checks_not_green",
+			RequiredChecks: []string{"e2e", "lint", "test"},
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, statusPath, http.StatusOK, github.CombinedStatus{Statuses: []*github.RepoStatus{status("lint", "success")}})
+				fake.on(http.MethodGet, checkRunsPath, http.StatusOK, github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{checkRun("test", "completed", "failure")}})
+			},
+			ExpectedSkipReason: promotion.SkipNotReady,
+			ExpectedBody:       "Promotion not ready: missing: e2e; failing: test",
+		},
+		{
+			Name:           "configured_checks_green",
+			RequiredChecks: []string{"lint", "test"},
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, statusPath, http.StatusOK, github.CombinedStatus{Statuses: []*github.RepoStatus{status("lint", "success")}})
+				fake.on(http.MethodGet, checkRunsPath, http.StatusOK, github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{checkRun("test", "completed", "success")}})
+			},
+			ExpectedBody:        "Promotion complete",
+			ExpectedFastForward: true,
+		},
+		{
+			Name: "inherited_checks_not_green",
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, protectionPath, http.StatusOK, github.RequiredStatusChecks{
+					Contexts: &[]string{"lint"},
+					Checks:   &[]*github.RequiredStatusCheck{{Context: "test"}},
+				})
+				fake.on(http.MethodGet, rulesPath, http.StatusOK, []map[string]any{{
+					"type":       "required_status_checks",
+					"parameters": map[string]any{"required_status_checks": []map[string]any{{"context": "e2e"}}, "strict_required_status_checks_policy": false},
+				}})
+				fake.on(http.MethodGet, statusPath, http.StatusOK, github.CombinedStatus{Statuses: []*github.RepoStatus{status("lint", "failure")}})
+				fake.on(http.MethodGet, checkRunsPath, http.StatusOK, github.ListCheckRunsResults{CheckRuns: []*github.CheckRun{checkRun("test", "in_progress", "")}})
+			},
+			ExpectedSkipReason: promotion.SkipNotReady,
+			ExpectedBody:       "Promotion not ready: missing: e2e; pending: test; failing: lint",
+		},
+		{
+			Name: "unprotected_branch",
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, protectionPath, http.StatusForbidden, map[string]string{"message": "Resource not accessible by integration"})
+			},
+			ExpectedBody:        "Promotion complete",
+			ExpectedFastForward: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			setStage(t, "staging", func(stage *config.Stage) { stage.RequiredChecks = tc.RequiredChecks })
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+			tc.Setup(fake)
+			bus := newPromotionBus(t, fake, pullRequest(1, "main", "staging", "c0ffee"))
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewFastForwarderPostProcessor(newController(t)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedSkipReason, skipReason(bus))
+			assert.Equal(t, http.StatusOK, bus.Response.StatusCode)
+			assert.Equal(t, tc.ExpectedBody, bus.Response.Body)
+			// Configured required checks take precedence over those of the branch protection and rulesets
+			assert.Equal(t, tc.RequiredChecks == nil, len(fake.received(http.MethodGet, protectionPath)) > 0)
+			assert.Equal(t, tc.ExpectedFastForward, len(fake.received(http.MethodPatch, fastForwardPath)) > 0)
+		})
+	}
+}
+
+func TestFastForwarderPostProcessorDryRun(t *testing.T) {
+	fake := newFakeGitHub(t)
+	fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+	fake.onRef("staging", "beef")
+	fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(1, "main", "staging", "c0ffee"))
+	fake.on(http.MethodPatch, fastForwardPath, http.StatusOK, github.Reference{})
+	bus := fake.bus(t, event.Push, &github.PushEvent{Ref: new("refs/heads/main"), After: new("c0ffee")}, "main", "staging", "production")
+	// As the handler does in dry-run mode, the plan is carried by both the bus and the context
+	bus.Plan = new(promotion.Plan)
+	ctx := promotion.ContextWithPlan(context.Background(), bus.Plan)
+	controller := newController(t)
+
+	bus, err := processor.Process(ctx, helpers.NewNoopLogger(), bus, processor.NewPushEventProcessor(controller), processor.NewFastForwarderPostProcessor(controller))
+	require.NoError(t, err)
+	assert.Equal(t, promotion.Success, bus.EventStatus)
+	actions := bus.Plan.Actions()
+	require.Len(t, actions, 2)
+	assert.Equal(t, "create-pull-request", actions[0].Action)
+	assert.Equal(t, "refs/heads/staging", actions[0].Ref)
+	assert.Equal(t, "fast-forward", actions[1].Action)
+	assert.Equal(t, "refs/heads/staging", actions[1].Ref)
+	assert.Equal(t, "c0ffee", actions[1].SHA)
+	// Mutations are planned rather than executed
+	assert.Empty(t, fake.receivedPrefix(http.MethodPost, "/"))
+	assert.Empty(t, fake.receivedPrefix(http.MethodPatch, "/"))
+}
@@ -1,9 +1,10 @@
 package processor
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"log/slog"
-	"net/http"
 	"strings"
 
 	"github.com/isometry/gh-promotion-app/internal/config"
@@ -16,6 +17,12 @@ import (
 type s3UploaderPostProcessor struct {
 	logger        *slog.Logger
 	awsController *aws.Controller
+	options       s3UploaderOptions
+}
+
+type s3UploaderOptions struct {
+	// BucketName overrides the globally configured S3 bucket.
+	BucketName string `yaml:"bucketName,omitempty"`
 }
 
 // NewS3UploaderPostProcessor constructs a Processor instance for handling S3 upload post-processing with optional configurations.
@@ -25,11 +32,19 @@ func NewS3UploaderPostProcessor(awsController *aws.Controller, opts ...Option) P
 	return _inst
 }
 
+func (p *s3UploaderPostProcessor) Name() string {
+	return "s3-uploader"
+}
+
 func (p *s3UploaderPostProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("post-processor:s3-uploader")
 }
 
-func (p *s3UploaderPostProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *s3UploaderPostProcessor) Configure(options map[string]any) error {
+	return decodeOptions(options, &p.options)
+}
+
+func (p *s3UploaderPostProcessor) Process(ctx context.Context, req any) (bus *promotion.Bus, err error) {
 	parsedBus, ok := req.(*promotion.Bus)
 	if !ok {
 		return bus, promotion.NewInternalErrorf("invalid event type. expected S3UploadRequest got %T", req)
@@ -43,6 +58,11 @@ func (p *s3UploaderPostProcessor) Process(req any) (bus *promotion.Bus, err erro
 		return bus, nil
 	}
 
+	if bus.IsFork() {
+		p.logger.Debug("ignoring fork of an event archived with its bus")
+		return bus, nil
+	}
+
 	p.logger.Debug("processing s3 upload...")
 
 	if bus.Context.Owner == nil || bus.Context.Repository == nil {
@@ -51,16 +71,16 @@ func (p *s3UploaderPostProcessor) Process(req any) (bus *promotion.Bus, err erro
 		return bus, promotion.NewInternalError(msg)
 	}
 
-	s3cap.BucketName = strings.TrimSpace(s3cap.BucketName)
+	s3cap.BucketName = strings.TrimSpace(cmp.Or(p.options.BucketName, s3cap.BucketName))
 	if s3cap.BucketName == "" {
 		p.logger.Error("s3 bucket name was left empty but upload is enabled")
 		return bus, nil
 	}
 
 	entryID := fmt.Sprintf("%s/%s/%s", *bus.Context.Owner, *bus.Context.Repository, bus.EventType)
-	if err = p.awsController.PutS3Object(entryID, s3cap.BucketName, body); err != nil {
+	if err = p.awsController.PutS3Object(ctx, entryID, s3cap.BucketName, body); err != nil {
 		p.logger.Warn("failed to store event in S3", slog.Any("error", err))
-		bus.Response = models.Response{Body: err.Error(), StatusCode: http.StatusInternalServerError}
+		bus.Response = models.Response{Body: err.Error(), StatusCode: promotion.StatusCode(err)}
 	}
 	return bus, nil
 }
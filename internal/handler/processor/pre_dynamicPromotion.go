@@ -1,10 +1,12 @@
 package processor
 
 import (
+	"context"
 	"log/slog"
 
 	"github.com/isometry/gh-promotion-app/internal/config"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
 )
@@ -21,25 +23,38 @@ func NewDynamicPromotionPreProcessor(githubController *github.Controller, opts .
 	return _inst
 }
 
+func (p *dynamicPromotionProcessor) Name() string {
+	return "dynamic-promotion"
+}
+
 func (p *dynamicPromotionProcessor) SetLogger(logger *slog.Logger) {
 	p.logger = logger.WithGroup("pre-processor:dynamic-promotion")
 }
 
-func (p *dynamicPromotionProcessor) Process(req any) (bus *promotion.Bus, err error) {
+func (p *dynamicPromotionProcessor) Process(_ context.Context, req any) (bus *promotion.Bus, err error) {
 	parsedBus, ok := req.(*promotion.Bus)
 	if !ok {
 		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
 	}
 	bus = parsedBus
 
+	// Installation events concern every repository of the installation: their processors resolve the promoter of each
+	if event.IsInstallationEvent(bus.EventType) {
+		p.logger.Debug("skipping dynamic promotion of installation event...")
+		return bus, nil
+	}
+
+	bus.Context.Promoter = NewPromoter(p.logger, bus.Repository.CustomProperties)
+	return bus, nil
+}
+
+// NewPromoter returns the promoter of a repository with the given custom properties.
+func NewPromoter(logger *slog.Logger, props map[string]any) *promotion.Promoter {
 	// If dynamic promotion is enabled use custom properties to set the promoter, else use the default promoter
 	if config.Promotion.Dynamic.Enabled {
-		p.logger.Debug("processing dynamic promotion, assigned promoter...")
-		bus.Context.Promoter = promotion.NewDynamicPromoter(p.logger, bus.Repository.CustomProperties,
-			config.Promotion.Dynamic.Key, config.Promotion.Dynamic.Class)
-	} else {
-		p.logger.Info("dynamic promotion is disabled... defaulting to standard promoter")
-		bus.Context.Promoter = promotion.NewDefaultPromoter()
+		logger.Debug("processing dynamic promotion, assigned promoter...")
+		return promotion.NewDynamicPromoter(logger, props, config.Promotion.Dynamic.Key, config.Promotion.Dynamic.Class)
 	}
-	return bus, nil
+	logger.Info("dynamic promotion is disabled... defaulting to standard promoter")
+	return promotion.NewDefaultPromoter(logger)
 }
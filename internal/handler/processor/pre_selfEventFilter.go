@@ -0,0 +1,128 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+type selfEventFilterProcessor struct {
+	logger           *slog.Logger
+	githubController *internalGitHub.Controller
+}
+
+// NewSelfEventFilterPreProcessor initializes a Processor skipping the status, check_suite and check_run events echoing
+// the feedback of the app, so that the commit statuses and check runs it creates do not trigger promotions of their own.
+func NewSelfEventFilterPreProcessor(githubController *internalGitHub.Controller, opts ...Option) Processor {
+	_inst := &selfEventFilterProcessor{githubController: githubController, logger: helpers.NewNoopLogger()}
+	applyOpts(_inst, opts...)
+	return _inst
+}
+
+func (p *selfEventFilterProcessor) Name() string {
+	return "self-event-filter"
+}
+
+func (p *selfEventFilterProcessor) SetLogger(logger *slog.Logger) {
+	p.logger = logger.WithGroup("pre-processor:self-event-filter")
+}
+
+func (p *selfEventFilterProcessor) Process(_ context.Context, req any) (bus *promotion.Bus, err error) {
+	if p.githubController == nil {
+		return nil, promotion.NewInternalErrorf("githubController is nil")
+	}
+	parsedBus, ok := req.(*promotion.Bus)
+	if !ok {
+		return bus, promotion.NewInternalErrorf("invalid event type. expected *promotion.Bus got %T", req)
+	}
+	bus = parsedBus
+
+	if !config.Promotion.SelfEvents.Enabled {
+		return bus, nil
+	}
+
+	var reason string
+	switch e := bus.Event.(type) {
+	case *github.StatusEvent:
+		reason = p.senderReason(e.GetSender(), nil)
+		if reason == "" && p.isIgnoredContext(e.GetContext()) {
+			reason = fmt.Sprintf("commit status context %q is ignored", e.GetContext())
+		}
+	case *github.CheckSuiteEvent:
+		reason = p.senderReason(e.GetSender(), e.GetCheckSuite().GetApp())
+		if reason == "" && p.isOwnApp(e.GetCheckSuite().GetApp()) {
+			reason = "check suite was created by the promotion app"
+		}
+	case *github.CheckRunEvent:
+		// Retries of the promotion check runs are requested by users and must go through
+		if action := e.GetAction(); action == "rerequested" || action == "requested_action" {
+			reason = p.senderReason(e.GetSender(), nil)
+			break
+		}
+		reason = p.senderReason(e.GetSender(), e.GetCheckRun().GetApp())
+		if reason == "" && p.isOwnApp(e.GetCheckRun().GetApp()) {
+			reason = "check run was created by the promotion app"
+		}
+	default:
+		// Pushes of the app, e.g. fast-forwards, must keep cascading promotions through the stages
+		return bus, nil
+	}
+
+	if reason != "" {
+		p.logger.Info("ignoring self event...", slog.String("reason", reason))
+		bus.Skip(promotion.SkipSelfEvent, reason)
+	}
+	return bus, nil
+}
+
+// senderReason explains why events of the sender are ignored, if they are. The app, if any, is the app reported by the
+// event, whose bot user is recognised when it is the promotion app.
+func (p *selfEventFilterProcessor) senderReason(sender *github.User, app *github.App) string {
+	login := sender.GetLogin()
+	if login == "" {
+		return ""
+	}
+	if botLogin := config.Promotion.SelfEvents.BotLogin; botLogin != "" && strings.EqualFold(login, botLogin) {
+		return fmt.Sprintf("sender %s is the promotion app", login)
+	}
+	if p.isOwnApp(app) && strings.EqualFold(login, app.GetSlug()+"[bot]") {
+		return fmt.Sprintf("sender %s is the promotion app", login)
+	}
+	if slices.ContainsFunc(config.Promotion.SelfEvents.IgnoredSenders, func(ignored string) bool { return strings.EqualFold(login, ignored) }) {
+		return fmt.Sprintf("sender %s is ignored", login)
+	}
+	return ""
+}
+
+// isOwnApp reports whether the app is the promotion app, identified by the app ID of the credentials.
+func (p *selfEventFilterProcessor) isOwnApp(app *github.App) bool {
+	return p.githubController.AppID != 0 && app.GetID() == p.githubController.AppID
+}
+
+// isIgnoredContext reports whether the commit status context is the commit status feedback context, or an ignored context.
+func (p *selfEventFilterProcessor) isIgnoredContext(statusContext string) bool {
+	if feedback := config.Promotion.Feedback.CommitStatus; feedback.Enabled && feedback.Context != "" && contextPattern(feedback.Context).MatchString(statusContext) {
+		return true
+	}
+	return slices.ContainsFunc(config.Promotion.SelfEvents.IgnoredContexts, func(pattern string) bool {
+		matched, _ := path.Match(pattern, statusContext)
+		return matched
+	})
+}
+
+// contextPattern turns a commit status context template into a regular expression matching any of its renderings.
+// Placeholders match any text, including the "/" of progress counters, e.g. "2/3", and of stage branches.
+func contextPattern(template string) *regexp.Regexp {
+	pattern := strings.NewReplacer(`\{source\}`, ".*", `\{target\}`, ".*", `\{progress\}`, ".*").Replace(regexp.QuoteMeta(template))
+	return regexp.MustCompile("^" + pattern + "$")
+}
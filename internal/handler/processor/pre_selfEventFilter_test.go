@@ -0,0 +1,201 @@
+package processor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextPattern(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Template   string
+		Matches    []string
+		Mismatches []string
+	}{
+		{
+			Name:       "source_and_target",
+			Template:   "{source}→{target}",
+			Matches:    []string{"main→staging", "staging→production", "release/v1→release/v2"},
+			Mismatches: []string{"ci/build", "main->staging"},
+		},
+		{
+			Name:       "progress",
+			Template:   "promotion/{target} {progress}",
+			Matches:    []string{"promotion/production 2/3"},
+			Mismatches: []string{"promotion/production", "release/production 2/3"},
+		},
+		{
+			Name:       "constant",
+			Template:   "promotion",
+			Matches:    []string{"promotion"},
+			Mismatches: []string{"promotion/staging", "my-promotion"},
+		},
+		{
+			Name:       "metacharacters",
+			Template:   `[promotion] {target}?*.\`,
+			Matches:    []string{`[promotion] staging?*.\`},
+			Mismatches: []string{`p staging?*.\`, `[promotion] stagingx*.\`, `[promotion] staging?*x\`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			pattern := processor.ContextPattern(tc.Template)
+			for _, statusContext := range tc.Matches {
+				assert.True(t, pattern.MatchString(statusContext), statusContext)
+			}
+			for _, statusContext := range tc.Mismatches {
+				assert.False(t, pattern.MatchString(statusContext), statusContext)
+			}
+		})
+	}
+}
+
+func TestSelfEventFilterPreProcessor(t *testing.T) {
+	const appID = int64(17)
+	ownApp := &github.App{ID: new(appID), Slug: new("promotion-app")}
+	otherApp := &github.App{ID: new(int64(23)), Slug: new("ci")}
+	user := &github.User{Login: new("alice")}
+	bot := &github.User{Login: new("promotion-app[bot]")}
+
+	testCases := []struct {
+		Name            string
+		Disabled        bool
+		BotLogin        string
+		IgnoredSenders  []string
+		IgnoredContexts []string
+		FeedbackContext string
+		EventType       event.Type
+		Event           any
+		ExpectedSkipped bool
+	}{
+		{
+			Name:            "status_feedback_context",
+			FeedbackContext: "{source}→{target}",
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("main→staging"), Sender: user},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:            "status_feedback_context_with_progress",
+			FeedbackContext: "promotion {progress}",
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("promotion 2/3"), Sender: user},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:            "status_ignored_context",
+			IgnoredContexts: []string{"deploy/*"},
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("deploy/staging"), Sender: user},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:            "status_other_context",
+			FeedbackContext: "{source}→{target}",
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("ci/build"), Sender: user},
+		},
+		{
+			Name:            "status_by_bot_login",
+			BotLogin:        "promotion-app[bot]",
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("ci/build"), Sender: bot},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:            "status_by_ignored_sender",
+			IgnoredSenders:  []string{"Alice"},
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("ci/build"), Sender: user},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:            "check_suite_of_own_app",
+			EventType:       event.CheckSuite,
+			Event:           &github.CheckSuiteEvent{Action: new("completed"), CheckSuite: &github.CheckSuite{App: ownApp}, Sender: user},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:      "check_suite_of_other_app",
+			EventType: event.CheckSuite,
+			Event:     &github.CheckSuiteEvent{Action: new("completed"), CheckSuite: &github.CheckSuite{App: otherApp}, Sender: user},
+		},
+		{
+			Name:            "check_run_of_own_app",
+			EventType:       event.CheckRun,
+			Event:           &github.CheckRunEvent{Action: new("completed"), CheckRun: &github.CheckRun{App: ownApp}, Sender: bot},
+			ExpectedSkipped: true,
+		},
+		{
+			Name:      "check_run_of_other_app",
+			EventType: event.CheckRun,
+			Event:     &github.CheckRunEvent{Action: new("completed"), CheckRun: &github.CheckRun{App: otherApp}, Sender: user},
+		},
+		{
+			Name:      "retry_of_own_check_run_by_user",
+			EventType: event.CheckRun,
+			Event:     &github.CheckRunEvent{Action: new("requested_action"), CheckRun: &github.CheckRun{App: ownApp}, Sender: user},
+		},
+		{
+			Name:      "rerun_of_own_check_run_by_user",
+			EventType: event.CheckRun,
+			Event:     &github.CheckRunEvent{Action: new("rerequested"), CheckRun: &github.CheckRun{App: ownApp}, Sender: user},
+		},
+		{
+			Name:            "rerun_by_ignored_sender",
+			IgnoredSenders:  []string{"alice"},
+			EventType:       event.CheckRun,
+			Event:           &github.CheckRunEvent{Action: new("rerequested"), CheckRun: &github.CheckRun{App: ownApp}, Sender: user},
+			ExpectedSkipped: true,
+		},
+		{
+			// Fast-forwards of the app cascade promotions through the stages
+			Name:      "push_by_bot",
+			BotLogin:  "promotion-app[bot]",
+			EventType: event.Push,
+			Event:     &github.PushEvent{Sender: bot},
+		},
+		{
+			Name:            "disabled",
+			Disabled:        true,
+			FeedbackContext: "{source}→{target}",
+			EventType:       event.Status,
+			Event:           &github.StatusEvent{Context: new("main→staging"), Sender: user},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			restoreConfig(t)
+			config.Promotion.SelfEvents.Enabled = !tc.Disabled
+			config.Promotion.SelfEvents.BotLogin = tc.BotLogin
+			config.Promotion.SelfEvents.IgnoredSenders = tc.IgnoredSenders
+			config.Promotion.SelfEvents.IgnoredContexts = tc.IgnoredContexts
+			config.Promotion.Feedback.CommitStatus.Enabled = tc.FeedbackContext != ""
+			config.Promotion.Feedback.CommitStatus.Context = tc.FeedbackContext
+			controller := newController(t)
+			controller.AppID = appID
+			bus := newFakeGitHub(t).bus(t, tc.EventType, tc.Event, "main", "staging", "production")
+
+			bus, err := processor.Process(context.Background(), helpers.NewNoopLogger(), bus, processor.NewSelfEventFilterPreProcessor(controller))
+			require.NoError(t, err)
+			if tc.ExpectedSkipped {
+				assert.Equal(t, promotion.Skipped, bus.EventStatus)
+				assert.Equal(t, promotion.SkipSelfEvent, skipReason(bus))
+				return
+			}
+			assert.NotEqual(t, promotion.Skipped, bus.EventStatus)
+			assert.Empty(t, skipReason(bus))
+		})
+	}
+}
@@ -2,7 +2,9 @@
 package processor
 
 import (
+	"context"
 	"log/slog"
+	"time"
 
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
@@ -12,9 +14,12 @@ import (
 type Option = func(Processor)
 
 // Processor is an interface that defines a method to process a request.
+// The context carries the request deadline and must be honoured by every outbound call.
 type Processor interface {
+	// Name returns the name identifying the processor in the decision trace.
+	Name() string
 	SetLogger(logger *slog.Logger)
-	Process(any) (*promotion.Bus, error)
+	Process(ctx context.Context, req any) (*promotion.Bus, error)
 }
 
 // AuthRequest is a struct that represents an authentication request.
@@ -22,6 +27,8 @@ type AuthRequest struct {
 	Body            []byte
 	Headers         map[string]string
 	EventProcessors map[event.Type][]Processor
+	// SkipSignatureValidation accepts payloads without a valid webhook signature, e.g. replayed payloads.
+	SkipSignatureValidation bool
 }
 
 // FeedbackRequest is a struct that represents a feedback request.
@@ -31,16 +38,38 @@ type FeedbackRequest struct {
 }
 
 // Process is a function that processes a request using a list of processors.
-func Process(logger *slog.Logger, req any, processors ...Processor) (*promotion.Bus, error) {
+// It stops before the next processor once the context is done.
+// The outcome of each processor is recorded in the decision trace of the resulting Bus.
+func Process(ctx context.Context, logger *slog.Logger, req any, processors ...Processor) (*promotion.Bus, error) {
 	var err error
 	for _, p := range processors {
+		if err = ctx.Err(); err != nil {
+			break
+		}
 		p.SetLogger(logger)
-		req, err = p.Process(req)
+		var status promotion.EventStatus
+		if bus, ok := req.(*promotion.Bus); ok {
+			status = bus.EventStatus
+		}
+		started := time.Now()
+		req, err = p.Process(withPlan(ctx, req), req)
+		if bus, ok := req.(*promotion.Bus); ok && bus != nil {
+			bus.RecordStep(p.Name(), started, status, err)
+		}
 		if err != nil {
-			return req.(*promotion.Bus), err
+			break
 		}
 	}
-	return req.(*promotion.Bus), err
+	bus, _ := req.(*promotion.Bus)
+	return bus, err
+}
+
+// withPlan returns a context carrying the dry-run plan of the bus, if any, e.g. when a command asks for an explanation.
+func withPlan(ctx context.Context, req any) context.Context {
+	if bus, ok := req.(*promotion.Bus); ok && bus != nil && bus.Plan != nil && promotion.PlanFromContext(ctx) == nil {
+		return promotion.ContextWithPlan(ctx, bus.Plan)
+	}
+	return ctx
 }
 
 func applyOpts(m Processor, opts ...Option) {
@@ -0,0 +1,162 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+// openPromotionRequest ensures a promotion request is open from the head ref of the bus context to its base ref,
+// creating the missing target ref if enabled. The promotion request is set on the bus context.
+func openPromotionRequest(ctx context.Context, githubController *internalGitHub.Controller, logger *slog.Logger, bus *promotion.Bus) (err error) {
+	// Create missing target ref if the feature is enabled and the target ref does not exist
+	if config.Promotion.Push.CreateTargetRef && !githubController.PromotionTargetRefExists(ctx, bus.Context) {
+		if _, err = githubController.CreatePromotionTargetRef(ctx, bus.Context); err != nil {
+			logger.Error("failed to create target ref", slog.Any("error", err))
+			return err
+		}
+	}
+
+	bus.Context.PullRequest, err = githubController.FindPullRequest(ctx, bus.Context)
+	if err == nil {
+		// PR already exists covering this push event
+		logger.Info("skipping recreation of existing promotion request...", slog.String("url", *bus.Context.PullRequest.URL))
+		return nil
+	}
+	if !errors.Is(err, internalGitHub.ErrNoPromotionRequest) {
+		logger.Error("failed to find promotion PR", slog.Any("error", err))
+		return err
+	}
+
+	logger.Debug("creating promotion PR...")
+	if bus.Context.PullRequest, err = githubController.CreatePullRequest(ctx, bus); err != nil {
+		logger.Error("failed to create promotion PR", slog.Any("error", err))
+		return err
+	}
+	logger.Info("created promotion PR", slog.String("url", *bus.Context.PullRequest.URL))
+	return nil
+}
+
+// openStagePromotionRequest opens the promotion request between the stages set as head and base refs of the bus context,
+// unless the source stage is not ahead of the target stage, or the target stage is missing and may not be created.
+// The head SHA is resolved from the source stage. It reports whether a promotion request is open.
+func openStagePromotionRequest(ctx context.Context, githubController *internalGitHub.Controller, logger *slog.Logger, bus *promotion.Bus) (bool, error) {
+	pCtx := bus.Context
+	logger = logger.With(slog.String("source", *pCtx.HeadRef), slog.String("target", *pCtx.BaseRef))
+
+	headSHA, err := githubController.GetPromotionSourceRefSHA(ctx, pCtx)
+	if err != nil {
+		return false, err
+	}
+	pCtx.HeadSHA = &headSHA
+
+	if githubController.PromotionTargetRefExists(ctx, pCtx) {
+		aheadBy, err := githubController.PromotionSourceAheadBy(ctx, pCtx)
+		if err != nil {
+			return false, err
+		}
+		if aheadBy == 0 {
+			logger.Debug("source stage is not ahead of the target stage")
+			return false, nil
+		}
+	} else if !config.Promotion.Push.CreateTargetRef {
+		logger.Info("ignoring stage pair with a missing target ref")
+		return false, nil
+	}
+
+	if err = openPromotionRequest(ctx, githubController, logger, bus); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// openStagePair opens the promotion request from the source to the target stage of the repository of the bus,
+// serialised with the other events targeting the target stage. It reports whether a promotion request is open.
+func openStagePair(ctx context.Context, githubController *internalGitHub.Controller, logger *slog.Logger, bus *promotion.Bus, source, target string) (bool, error) {
+	pCtx := *bus.Context
+	pCtx.HeadRef = helpers.NormaliseFullRefPtr(source)
+	pCtx.BaseRef = helpers.NormaliseFullRefPtr(target)
+	pCtx.HeadSHA = nil
+	pCtx.PullRequest = nil
+	pair := &promotion.Bus{Context: &pCtx, Repository: bus.Repository, Locker: bus.Locker}
+
+	if err := pair.LockBaseRef(ctx); err != nil {
+		return false, err
+	}
+	defer pair.Unlock()
+	return openStagePromotionRequest(ctx, githubController, logger, pair)
+}
+
+// reconcileStagePairs opens the promotion requests of every stage pair of the promoter whose source is ahead of its target.
+// It returns the number of open promotion requests.
+func reconcileStagePairs(ctx context.Context, githubController *internalGitHub.Controller, logger *slog.Logger, bus *promotion.Bus) (int, error) {
+	var opened int
+	for _, pair := range bus.Context.Promoter.Pairs() {
+		ok, err := openStagePair(ctx, githubController, logger, bus, pair.Source, pair.Target)
+		if err != nil {
+			return opened, fmt.Errorf("failed to reconcile %s → %s: %w", pair.Source, pair.Target, err)
+		}
+		if ok {
+			opened++
+		}
+	}
+	return opened, nil
+}
+
+// forkPromotionRequests sets the first of the promotion requests on the bus context and forks the bus for each of the
+// others, e.g. the promotion requests of a head SHA fanning out of a stage.
+func forkPromotionRequests(bus *promotion.Bus, prs []*github.PullRequest) {
+	for i, pr := range prs {
+		promoted := bus
+		if i > 0 {
+			promoted = bus.Fork()
+			bus.Forks = append(bus.Forks, promoted)
+		}
+		promoted.Context.HeadRef = helpers.NormaliseRefPtr(*pr.Head.Ref)
+		promoted.Context.BaseRef = helpers.NormaliseRefPtr(*pr.Base.Ref)
+		promoted.Context.HeadSHA = pr.Head.SHA
+		promoted.Context.PullRequest = pr
+	}
+}
+
+// closePromotionRequest closes the promotion request with a comment explaining the reason, and concludes its promotion feedback.
+func closePromotionRequest(ctx context.Context, githubController *internalGitHub.Controller, logger *slog.Logger, bus *promotion.Bus, pr *github.PullRequest, reason string) error {
+	number := pr.GetNumber()
+	logger = logger.With(slog.Int("number", number))
+
+	if err := githubController.CommentOnPullRequest(ctx, bus.Context, number, fmt.Sprintf("Closing this promotion request: %s.", reason)); err != nil {
+		logger.Warn("failed to explain the closure of the promotion request", slog.Any("error", err))
+	}
+	if err := githubController.ClosePullRequest(ctx, bus.Context, number); err != nil {
+		logger.Error("failed to close promotion request", slog.Any("error", err))
+		return err
+	}
+	pr.State = new("closed")
+	logger.Info("closed promotion request", slog.String("reason", reason))
+
+	// The pending feedback of the promotion request would otherwise never conclude
+	pCtx := *bus.Context
+	pCtx.PullRequest = pr
+	pCtx.HeadRef = pr.Head.Ref
+	pCtx.BaseRef = pr.Base.Ref
+	pCtx.HeadSHA = pr.Head.SHA
+	closed := &promotion.Bus{Context: &pCtx, Repository: bus.Repository, EventStatus: promotion.Failure, Error: errors.New(reason)}
+	if config.Promotion.Feedback.CheckRun.Enabled {
+		if err := githubController.SendPromotionFeedbackCheckRun(ctx, closed, internalGitHub.CheckRunConclusionCancelled, config.Promotion.Feedback.CheckRun.Name); err != nil {
+			logger.Warn("failed to send feedback check-run", slog.Any("error", err))
+		}
+	}
+	if config.Promotion.Feedback.CommitStatus.Enabled {
+		if err := githubController.SendPromotionFeedbackCommitStatus(ctx, closed, internalGitHub.CommitStatusFailure, config.Promotion.Feedback.CommitStatus.Context); err != nil {
+			logger.Warn("failed to send feedback commit-status", slog.Any("error", err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileStagePairs(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		CreateTargetRef bool
+		Setup           func(fake *fakeGitHub)
+		ExpectedOpen    int
+		ExpectedCreated []string
+		ExpectedRefs    []string
+		ExpectedError   string
+	}{
+		{
+			Name: "source_ahead",
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "beef")
+				fake.onRef("production", "beef")
+				fake.onCompare("staging", "main", "ahead", 1)
+				fake.onCompare("production", "staging", "identical", 0)
+			},
+			ExpectedOpen:    1,
+			ExpectedCreated: []string{`"head":"refs/heads/main","base":"refs/heads/staging"`},
+		},
+		{
+			Name: "existing_promotion_request",
+			Setup: func(fake *fakeGitHub) {
+				fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{pullRequest(1, "main", "staging", "c0ffee")})
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "beef")
+				fake.onRef("production", "beef")
+				fake.onCompare("staging", "main", "ahead", 1)
+				fake.onCompare("production", "staging", "identical", 0)
+			},
+			ExpectedOpen: 1,
+		},
+		{
+			Name: "missing_target_stage",
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "c0ffee")
+				fake.onCompare("staging", "main", "identical", 0)
+			},
+		},
+		{
+			Name:            "created_target_stage",
+			CreateTargetRef: true,
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("main", "c0ffee")
+				fake.onRef("staging", "c0ffee")
+				fake.onCompare("staging", "main", "identical", 0)
+				fake.on(http.MethodGet, "/repos/owner/repo/commits", http.StatusOK, []*github.RepositoryCommit{{SHA: new("c0ffee")}})
+				fake.on(http.MethodPost, "/repos/owner/repo/git/refs", http.StatusCreated, github.Reference{Ref: new("refs/heads/production")})
+			},
+			ExpectedOpen:    1,
+			ExpectedCreated: []string{`"head":"refs/heads/staging","base":"refs/heads/production"`},
+			ExpectedRefs:    []string{`"ref":"refs/heads/production"`},
+		},
+		{
+			Name: "missing_source_stage",
+			Setup: func(fake *fakeGitHub) {
+				fake.onRef("staging", "beef")
+			},
+			ExpectedError: "failed to reconcile main → staging",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			restoreConfig(t)
+			config.Promotion.Push.CreateTargetRef = tc.CreateTargetRef
+			fake := newFakeGitHub(t)
+			fake.on(http.MethodGet, "/repos/owner/repo/pulls", http.StatusOK, []*github.PullRequest{})
+			tc.Setup(fake)
+			fake.on(http.MethodPost, "/repos/owner/repo/pulls", http.StatusCreated, pullRequest(2, "main", "staging", "c0ffee"))
+			bus := fake.bus(t, event.Installation, &github.InstallationEvent{}, "main", "staging", "production")
+
+			open, err := processor.ReconcileStagePairs(context.Background(), newController(t), helpers.NewNoopLogger(), bus)
+			if tc.ExpectedError != "" {
+				assert.ErrorContains(t, err, tc.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedOpen, open)
+
+			created := fake.received(http.MethodPost, "/repos/owner/repo/pulls")
+			require.Len(t, created, len(tc.ExpectedCreated))
+			for i, expected := range tc.ExpectedCreated {
+				assert.Contains(t, created[i].Body, expected)
+			}
+			refs := fake.received(http.MethodPost, "/repos/owner/repo/git/refs")
+			require.Len(t, refs, len(tc.ExpectedRefs))
+			for i, expected := range tc.ExpectedRefs {
+				assert.Contains(t, refs[i].Body, expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/aws"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"go.yaml.in/yaml/v3"
+)
+
+// Dependencies holds the controllers made available to processor factories.
+type Dependencies struct {
+	GitHubController *github.Controller
+	AWSController    *aws.Controller
+}
+
+// Factory creates a new Processor instance from the given dependencies and options.
+type Factory func(deps Dependencies, opts ...Option) Processor
+
+// Configurable is implemented by processors accepting per-processor options from the pipeline configuration.
+type Configurable interface {
+	Configure(options map[string]any) error
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"self-event-filter": func(deps Dependencies, opts ...Option) Processor {
+			return NewSelfEventFilterPreProcessor(deps.GitHubController, opts...)
+		},
+		"dynamic-promotion": func(deps Dependencies, opts ...Option) Processor {
+			return NewDynamicPromotionPreProcessor(deps.GitHubController, opts...)
+		},
+		"push": func(deps Dependencies, opts ...Option) Processor {
+			return NewPushEventProcessor(deps.GitHubController, opts...)
+		},
+		"pull-request": func(deps Dependencies, opts ...Option) Processor {
+			return NewPullRequestEventProcessor(deps.GitHubController, opts...)
+		},
+		"pull-request-review": func(deps Dependencies, opts ...Option) Processor {
+			return NewPullRequestReviewEventProcessor(deps.GitHubController, opts...)
+		},
+		"check-suite": func(deps Dependencies, opts ...Option) Processor {
+			return NewCheckSuiteEventProcessor(deps.GitHubController, opts...)
+		},
+		"check-run-event": func(deps Dependencies, opts ...Option) Processor {
+			return NewCheckRunEventProcessor(deps.GitHubController, opts...)
+		},
+		"merge-group": func(deps Dependencies, opts ...Option) Processor {
+			return NewMergeGroupEventProcessor(deps.GitHubController, opts...)
+		},
+		"issue-comment": func(deps Dependencies, opts ...Option) Processor {
+			return NewIssueCommentEventProcessor(deps.GitHubController, opts...)
+		},
+		"create": func(deps Dependencies, opts ...Option) Processor {
+			return NewCreateEventProcessor(deps.GitHubController, opts...)
+		},
+		"delete": func(deps Dependencies, opts ...Option) Processor {
+			return NewDeleteEventProcessor(deps.GitHubController, opts...)
+		},
+		"custom-property-values": func(deps Dependencies, opts ...Option) Processor {
+			return NewCustomPropertyValuesEventProcessor(deps.GitHubController, opts...)
+		},
+		"installation": func(deps Dependencies, opts ...Option) Processor {
+			return NewInstallationEventProcessor(deps.GitHubController, opts...)
+		},
+		"installation-repositories": func(deps Dependencies, opts ...Option) Processor {
+			return NewInstallationRepositoriesEventProcessor(deps.GitHubController, opts...)
+		},
+		"deployment-status": func(deps Dependencies, opts ...Option) Processor {
+			return NewDeploymentStatusEventProcessor(deps.GitHubController, opts...)
+		},
+		"status": func(deps Dependencies, opts ...Option) Processor {
+			return NewStatusEventProcessor(deps.GitHubController, opts...)
+		},
+		"workflow-run": func(deps Dependencies, opts ...Option) Processor {
+			return NewWorkflowRunEventProcessor(deps.GitHubController, opts...)
+		},
+		"fast-forwarder": func(deps Dependencies, opts ...Option) Processor {
+			return NewFastForwarderPostProcessor(deps.GitHubController, opts...)
+		},
+		"s3-uploader": func(deps Dependencies, opts ...Option) Processor {
+			return NewS3UploaderPostProcessor(deps.AWSController, opts...)
+		},
+		"commit-status": func(deps Dependencies, opts ...Option) Processor {
+			return NewCommitStatusFeedbackProcessor(deps.GitHubController, opts...)
+		},
+		"check-run-feedback": func(deps Dependencies, opts ...Option) Processor {
+			return NewCheckRunFeedbackProcessor(deps.GitHubController, opts...)
+		},
+		"chat-ops": func(deps Dependencies, opts ...Option) Processor {
+			return NewChatOpsFeedbackProcessor(deps.GitHubController, opts...)
+		},
+	}
+)
+
+// Register adds a processor factory to the registry under the given name, replacing any existing entry.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Registered returns the sorted names of all registered processors.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New creates the processor registered under the given name and applies its per-processor options.
+func New(name string, deps Dependencies, options map[string]any, opts ...Option) (Processor, error) {
+	registryMu.RLock()
+	factory, found := registry[name]
+	registryMu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown processor %q. registered processors: %v", name, Registered())
+	}
+
+	p := factory(deps, opts...)
+	if len(options) == 0 {
+		return p, nil
+	}
+	configurable, ok := p.(Configurable)
+	if !ok {
+		return nil, fmt.Errorf("processor %q does not accept options", name)
+	}
+	if err := configurable.Configure(options); err != nil {
+		return nil, fmt.Errorf("invalid options for processor %q: %w", name, err)
+	}
+	return p, nil
+}
+
+// NewFromSteps creates the processors referenced by the given pipeline steps, preserving their order.
+func NewFromSteps(steps []config.PipelineStep, deps Dependencies) ([]Processor, error) {
+	processors := make([]Processor, 0, len(steps))
+	for _, step := range steps {
+		p, err := New(step.Name, deps, step.Options)
+		if err != nil {
+			return nil, err
+		}
+		processors = append(processors, p)
+	}
+	return processors, nil
+}
+
+// decodeOptions decodes the raw per-processor options into the given target, rejecting unknown keys.
+func decodeOptions(options map[string]any, target any) error {
+	raw, err := yaml.Marshal(options)
+	if err != nil {
+		return err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	decoder.KnownFields(true)
+	return decoder.Decode(target)
+}
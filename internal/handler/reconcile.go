@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	uGitHub "github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+// Reconcile recovers the promotions missed with lost webhooks. For every repository of the installations of the app, each
+// stage pair whose source is ahead of its target is run through the pipeline as a push to its source stage: missing
+// promotion requests are opened and ready ones are fast-forwarded, with the same feedback as webhooks.
+// Failures do not stop the reconciliation of the other repositories: they are reported and joined in the returned error.
+func (h *Handler) Reconcile(ctx context.Context) (*models.ReconcileReport, error) {
+	report := new(models.ReconcileReport)
+	if err := h.githubController.RetrieveCredentials(ctx); err != nil {
+		return report, fmt.Errorf("failed to refresh credentials. error: %w", err)
+	}
+	installations, err := h.reconcileInstallations(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	var errs []error
+	fail := func(err error) {
+		h.logger.Error("failed to reconcile", slog.Any("error", err))
+		errs = append(errs, err)
+		report.Errors = append(report.Errors, err.Error())
+	}
+	for _, installationID := range installations {
+		if err = ctx.Err(); err != nil {
+			fail(fmt.Errorf("%w: %w", promotion.ErrBudgetExhausted, err))
+			break
+		}
+		clients, err := h.githubController.GetInstallationClients(ctx, installationID)
+		if err != nil {
+			fail(fmt.Errorf("installation %d: %w", installationID, err))
+			continue
+		}
+		repos, err := h.reconcileRepositories(ctx, clients)
+		if err != nil {
+			fail(fmt.Errorf("installation %d: %w", installationID, err))
+			continue
+		}
+		report.Installations++
+
+		for _, fullName := range repos {
+			logger := h.logger.With(slog.Int64("installationId", installationID), slog.String("repository", fullName))
+			promotions, err := h.reconcileRepository(ctx, logger, installationID, clients, fullName)
+			report.Repositories++
+			report.Promotions = append(report.Promotions, promotions...)
+			if err != nil {
+				fail(fmt.Errorf("%s: %w", fullName, err))
+			}
+		}
+	}
+
+	h.logger.Info("reconciliation complete",
+		slog.Int("installations", report.Installations),
+		slog.Int("repositories", report.Repositories),
+		slog.Int("promotions", len(report.Promotions)),
+		slog.Int("errors", len(report.Errors)))
+	return report, errors.Join(errs...)
+}
+
+// reconcileInstallations returns the IDs of the installations to reconcile: the configured ones, else every installation
+// of the app that is not suspended.
+func (h *Handler) reconcileInstallations(ctx context.Context) ([]int64, error) {
+	if h.isTokenAuth() {
+		// Personal access tokens are not bound to an installation, and cannot list the repositories they have access to
+		if len(config.Reconcile.Repositories) == 0 {
+			return nil, promotion.NewErrorf(promotion.KindInvalidInput, "reconcile.repositories is required in token auth mode")
+		}
+		return []int64{0}, nil
+	}
+	if len(config.Reconcile.Installations) > 0 {
+		return config.Reconcile.Installations, nil
+	}
+
+	installations, err := h.githubController.ListInstallations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installations. error: %w", err)
+	}
+	ids := make([]int64, 0, len(installations))
+	for _, installation := range installations {
+		if installation.SuspendedAt != nil {
+			h.logger.Debug("ignoring suspended installation", slog.Int64("installationId", installation.GetID()))
+			continue
+		}
+		ids = append(ids, installation.GetID())
+	}
+	return ids, nil
+}
+
+// reconcileRepositories returns the full names of the repositories of the installation to reconcile, restricted to
+// config.Reconcile.Repositories if set. Archived repositories are ignored.
+func (h *Handler) reconcileRepositories(ctx context.Context, clients *github.Client) ([]string, error) {
+	if h.isTokenAuth() {
+		return config.Reconcile.Repositories, nil
+	}
+
+	repos, err := h.githubController.ListInstallationRepositories(ctx, clients)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		if repo.GetArchived() {
+			continue
+		}
+		if filter := config.Reconcile.Repositories; len(filter) > 0 && !slices.ContainsFunc(filter, func(name string) bool { return strings.EqualFold(name, repo.GetFullName()) }) {
+			continue
+		}
+		names = append(names, repo.GetFullName())
+	}
+	return names, nil
+}
+
+// reconcileRepository reconciles every stage pair of the promoter rebuilt from the custom properties of the repository.
+// The stage pairs are reconciled in topological order, so that a fast-forwarded stage cascades into the next ones.
+func (h *Handler) reconcileRepository(ctx context.Context, logger *slog.Logger, installationID int64, clients *github.Client, fullName string) ([]models.ReconciledPromotion, error) {
+	owner, name, found := strings.Cut(fullName, "/")
+	if !found {
+		return nil, promotion.NewErrorf(promotion.KindInvalidInput, "invalid repository %q. expected owner/name", fullName)
+	}
+	pCtx := &promotion.Context{
+		Owner:      &owner,
+		Repository: &name,
+		Logger:     logger.WithGroup("runtime:promotion"),
+		ClientV3:   clients.V3,
+		ClientV4:   clients.V4,
+	}
+	repo, err := h.githubController.GetRepository(ctx, pCtx)
+	if err != nil {
+		return nil, err
+	}
+	pairs := processor.NewPromoter(logger, repo.CustomProperties).Pairs()
+
+	var (
+		promotions []models.ReconciledPromotion
+		errs       []error
+	)
+	for _, pair := range pairs {
+		source, target := pair.Source, pair.Target
+		reconciled, err := h.reconcileStagePair(ctx, logger.With(slog.String("source", source), slog.String("target", target)), installationID, pCtx, repo, source, target)
+		if reconciled != nil {
+			promotions = append(promotions, *reconciled)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to reconcile %s → %s: %w", source, target, err))
+		}
+	}
+	return promotions, errors.Join(errs...)
+}
+
+// reconcileStagePair runs a push of the source stage through the pipeline, unless the source stage is missing or not
+// ahead of the target stage, or the target stage is missing and may not be created. The reconciled promotion is nil if
+// the stage pair needs no reconciliation.
+func (h *Handler) reconcileStagePair(ctx context.Context, logger *slog.Logger, installationID int64, repoCtx *promotion.Context, repo *uGitHub.Repository, source, target string) (*models.ReconciledPromotion, error) {
+	pCtx := *repoCtx
+	pCtx.HeadRef = helpers.NormaliseFullRefPtr(source)
+	pCtx.BaseRef = helpers.NormaliseFullRefPtr(target)
+
+	headSHA, err := h.githubController.GetPromotionSourceRefSHA(ctx, &pCtx)
+	if err != nil {
+		if promotion.Classify(err) == promotion.KindInvalidInput {
+			logger.Debug("ignoring stage pair with a missing source ref")
+			return nil, nil
+		}
+		return nil, err
+	}
+	if h.githubController.PromotionTargetRefExists(ctx, &pCtx) {
+		aheadBy, err := h.githubController.PromotionSourceAheadBy(ctx, &pCtx)
+		if err != nil {
+			return nil, err
+		}
+		if aheadBy == 0 {
+			logger.Debug("source stage is not ahead of the target stage")
+			return nil, nil
+		}
+	} else if !config.Promotion.Push.CreateTargetRef {
+		logger.Info("ignoring stage pair with a missing target ref")
+		return nil, nil
+	}
+
+	bus, err := h.Run(ctx, newReconcileBus(&pCtx, installationID, repo, headSHA))
+	if bus == nil {
+		bus = new(promotion.Bus)
+	}
+	reconciled := &models.ReconciledPromotion{
+		Repository: repo.GetFullName(),
+		Source:     source,
+		Target:     target,
+		HeadSHA:    headSHA,
+		Status:     string(bus.EventStatus),
+		Message:    bus.Response.Body,
+		Plan:       bus.Response.Plan,
+		Trace:      bus.Trace,
+	}
+	if err != nil {
+		reconciled.Error = err.Error()
+	}
+	logger.Info("reconciled stage pair", slog.String("headSHA", headSHA), slog.String("status", reconciled.Status))
+	return reconciled, err
+}
+
+// newReconcileBus returns the Bus of a push of the source stage of the promotion context to the given head SHA, as
+// authenticated by the auth validator. It carries no delivery ID, so that it bypasses the idempotency store.
+func newReconcileBus(pCtx *promotion.Context, installationID int64, repo *uGitHub.Repository, headSHA string) *promotion.Bus {
+	evt := &uGitHub.PushEvent{
+		Ref:   pCtx.HeadRef,
+		After: &headSHA,
+		Repo: &uGitHub.PushEventRepository{
+			Name:             repo.Name,
+			FullName:         repo.FullName,
+			Owner:            &uGitHub.User{Login: pCtx.Owner},
+			CustomProperties: repo.CustomProperties,
+		},
+		Installation: &uGitHub.Installation{ID: &installationID},
+	}
+	// The payload is only used by the S3 uploader, which archives it as for webhooks
+	body, _ := json.Marshal(evt)
+
+	return &promotion.Bus{
+		EventType:   event.Push,
+		Event:       evt,
+		EventStatus: promotion.Error,
+		Body:        body,
+		Headers:     map[string]string{strings.ToLower(uGitHub.EventTypeHeader): string(event.Push)},
+		Repository: &models.RepositoryContext{
+			Name:     repo.Name,
+			FullName: repo.FullName,
+			Owner: &struct {
+				Login *string `json:"login,omitempty"`
+			}{Login: pCtx.Owner},
+			CustomProperties: repo.CustomProperties,
+		},
+		Context: &promotion.Context{
+			EventType:  evt,
+			Owner:      pCtx.Owner,
+			Repository: pCtx.Repository,
+			Logger:     pCtx.Logger,
+			ClientV3:   pCtx.ClientV3,
+			ClientV4:   pCtx.ClientV4,
+		},
+	}
+}
+
+// isTokenAuth reports whether the handler authenticates with a personal access token.
+func (h *Handler) isTokenAuth() bool {
+	return strings.TrimSpace(strings.ToLower(h.authMode)) == "token"
+}
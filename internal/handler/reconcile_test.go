@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	internalGitHub "github.com/isometry/gh-promotion-app/internal/controllers/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingEventProcessor records the buses run through the pipeline, and completes their promotion.
+type capturingEventProcessor struct {
+	buses *[]*promotion.Bus
+}
+
+func (p capturingEventProcessor) Name() string { return "capturing" }
+
+func (p capturingEventProcessor) SetLogger(*slog.Logger) {}
+
+func (p capturingEventProcessor) Process(_ context.Context, req any) (*promotion.Bus, error) {
+	bus := req.(*promotion.Bus)
+	*p.buses = append(*p.buses, bus)
+	bus.Response = models.Response{Body: "Promotion complete", StatusCode: http.StatusOK}
+	bus.EventStatus = promotion.Success
+	return bus, nil
+}
+
+// newFakeGitHub returns the clients of a fake GitHub API answering the given "METHOD /path" routes with JSON documents.
+// Unrouted requests are answered with 404 Not Found, as GitHub answers requests for missing resources.
+func newFakeGitHub(t *testing.T, routes map[string]any) *internalGitHub.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		body, found := routes[req.Method+" "+req.URL.Path]
+		if !found {
+			rw.WriteHeader(http.StatusNotFound)
+			_, _ = rw.Write([]byte(`{"message": "Not Found"}`))
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+
+	clientV3, err := github.NewClient(github.WithURLs(new(server.URL+"/"), new(server.URL+"/uploads/")))
+	require.NoError(t, err)
+	return &internalGitHub.Client{
+		V3: clientV3,
+		V4: githubv4.NewEnterpriseClient(server.URL+"/graphql", server.Client()),
+	}
+}
+
+// ref returns the reference of the branch at the given SHA.
+func ref(branch, sha string) github.Reference {
+	return github.Reference{Ref: new("refs/heads/" + branch), Object: &github.GitObject{SHA: &sha}}
+}
+
+// restoreReconcile restores the configurations read by the reconciliation once the test completes.
+func restoreReconcile(t *testing.T) {
+	t.Helper()
+	savedReconcile, savedPromotion := config.Reconcile, config.Promotion
+	t.Cleanup(func() {
+		config.Reconcile = savedReconcile
+		config.Promotion = savedPromotion
+	})
+	restorePipeline(t)
+}
+
+func TestReconcile(t *testing.T) {
+	var buses []*promotion.Bus
+	processor.Register("test-capturing", func(processor.Dependencies, ...processor.Option) processor.Processor {
+		return capturingEventProcessor{buses: &buses}
+	})
+	restoreReconcile(t)
+	config.Pipeline.Pre = nil
+	config.Pipeline.Events = map[string][]config.PipelineStep{string(event.Push): {{Name: "test-capturing"}}}
+	config.Pipeline.Post = nil
+	config.Pipeline.Feedback = nil
+	config.Promotion.Push.CreateTargetRef = false
+	config.Reconcile.Repositories = []string{"owner/repo", "owner/missing", "invalid"}
+
+	properties := map[string]any{config.Promotion.Dynamic.Key: "main > staging > production > canary; qa > production"}
+	cache := internalGitHub.NewClientCache()
+	// Personal access tokens are not bound to an installation
+	cache.Put(0, newFakeGitHub(t, map[string]any{
+		"GET /repos/owner/repo": github.Repository{
+			Name:             new("repo"),
+			FullName:         new("owner/repo"),
+			CustomProperties: properties,
+		},
+		// main is ahead of staging
+		"GET /repos/owner/repo/git/ref/heads/main":     ref("main", "c0ffee"),
+		"GET /repos/owner/repo/git/ref/heads/staging":  ref("staging", "beef"),
+		"GET /repos/owner/repo/compare/staging...main": github.CommitsComparison{Status: new("ahead"), AheadBy: new(2)},
+		// staging is not ahead of production
+		"GET /repos/owner/repo/git/ref/heads/production":     ref("production", "beef"),
+		"GET /repos/owner/repo/compare/production...staging": github.CommitsComparison{Status: new("identical"), AheadBy: new(0)},
+		// canary is missing and may not be created, qa is missing
+	}))
+	h, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"), handler.WithClientCache(cache))
+	require.NoError(t, err)
+
+	report, err := h.Reconcile(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, 1, report.Installations)
+	assert.Equal(t, 3, report.Repositories)
+	require.Len(t, report.Errors, 2)
+	assert.True(t, strings.HasPrefix(report.Errors[0], "owner/missing: "), report.Errors[0])
+	assert.Contains(t, report.Errors[1], `invalid repository "invalid"`)
+
+	// Only the stage pair whose source is ahead of its existing target is reconciled: stage pairs whose source is missing,
+	// whose source is not ahead, or whose target is missing are left alone
+	require.Len(t, report.Promotions, 1)
+	reconciled := report.Promotions[0]
+	assert.Equal(t, "owner/repo", reconciled.Repository)
+	assert.Equal(t, "main", reconciled.Source)
+	assert.Equal(t, "staging", reconciled.Target)
+	assert.Equal(t, "c0ffee", reconciled.HeadSHA)
+	assert.Equal(t, string(promotion.Success), reconciled.Status)
+	assert.Equal(t, "Promotion complete", reconciled.Message)
+	assert.Empty(t, reconciled.Error)
+
+	// The stage pair is run through the pipeline as a push of its source stage
+	require.Len(t, buses, 1)
+	bus := buses[0]
+	assert.Equal(t, event.Push, bus.EventType)
+	assert.Empty(t, bus.DeliveryID)
+	assert.Equal(t, string(event.Push), bus.Headers[strings.ToLower(github.EventTypeHeader)])
+	evt, ok := bus.Event.(*github.PushEvent)
+	require.True(t, ok)
+	assert.Equal(t, "refs/heads/main", evt.GetRef())
+	assert.Equal(t, "c0ffee", evt.GetAfter())
+	assert.Equal(t, "owner/repo", evt.GetRepo().GetFullName())
+	assert.Equal(t, "owner", evt.GetRepo().GetOwner().GetLogin())
+	assert.Equal(t, int64(0), evt.GetInstallation().GetID())
+	assert.Equal(t, properties, bus.Repository.CustomProperties)
+	assert.Equal(t, "owner", *bus.Context.Owner)
+	assert.Equal(t, "repo", *bus.Context.Repository)
+	assert.JSONEq(t, string(mustMarshal(t, evt)), string(bus.Body))
+}
+
+func TestReconcileRequiresRepositoriesInTokenAuthMode(t *testing.T) {
+	restoreReconcile(t)
+	config.Reconcile.Repositories = nil
+	h, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"))
+	require.NoError(t, err)
+
+	_, err = h.Reconcile(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, promotion.KindInvalidInput, promotion.Classify(err))
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -8,27 +8,38 @@ import (
 )
 
 type httpResponse struct {
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
+	Message string                 `json:"message"`
+	Error   string                 `json:"error,omitempty"`
+	Plan    []models.PlannedAction `json:"plan,omitempty"`
+	Trace   []models.TraceEntry    `json:"trace,omitempty"`
 }
 
-// RespondHTTP writes the response to the http.ResponseWriter.
-func RespondHTTP(rw http.ResponseWriter, response models.Response, err error) {
+// MarshalResponse returns the JSON document describing the response and the optional error.
+func MarshalResponse(response models.Response, err error) []byte {
 	hR := httpResponse{
 		Message: response.Body,
+		Plan:    response.Plan,
+		Trace:   response.Trace,
 	}
 	if err != nil {
 		hR.Error = err.Error()
 	}
 
 	respBody, _ := json.Marshal(hR) //nolint:errchkjson // Errors can be safely ignored in this context
+	return respBody
+}
+
+// RespondHTTP writes the response to the http.ResponseWriter.
+func RespondHTTP(rw http.ResponseWriter, response models.Response, err error) {
+	respBody := MarshalResponse(response, err)
 	statusCode := response.StatusCode
 	if statusCode == 0 {
 		statusCode = http.StatusOK
 	}
-	rw.WriteHeader(statusCode)
+	// Headers must be set before the status code is written
 	for k, v := range response.Headers {
 		rw.Header().Set(k, v)
 	}
+	rw.WriteHeader(statusCode)
 	_, _ = rw.Write(respBody)
 }
@@ -53,6 +53,19 @@ func TestNewHttpResponse(t *testing.T) {
 				Header:     "application/json",
 			},
 		},
+		{
+			Name: "with_dry_run_plan",
+			Response: models.Response{
+				StatusCode: http.StatusOK,
+				Body:       "Promotion complete",
+				Plan:       []models.PlannedAction{{Action: "fast-forward", Ref: "refs/heads/staging", SHA: "abc"}},
+			},
+			Expected: expectedResponse{
+				StatusCode: http.StatusOK,
+				Body:       `"plan":[{"action":"fast-forward","ref":"refs/heads/staging","sha":"abc"}]`,
+				Header:     "",
+			},
+		},
 		{
 			Name:     "with_empty_response_and_no_error",
 			Response: models.Response{},
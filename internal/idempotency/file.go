@@ -0,0 +1,72 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File is a Store persisting one JSON document per delivery in a local directory.
+type File struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewFile creates a Store persisting deliveries in dir, creating the directory if needed.
+// Records older than ttl are ignored; a zero ttl disables expiry.
+func NewFile(dir string, ttl time.Duration) (*File, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create idempotency store directory %s: %w", dir, err)
+	}
+	return &File{dir: dir, ttl: ttl}, nil
+}
+
+// Get implements Store.
+func (f *File) Get(_ context.Context, deliveryID string) (*Record, error) {
+	content, err := os.ReadFile(f.path(deliveryID))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read delivery %s: %w", deliveryID, err)
+	}
+	var record Record
+	if err = json.Unmarshal(content, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode delivery %s: %w", deliveryID, err)
+	}
+	if expired(&record, f.ttl) {
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
+
+// Put implements Store. The record is written to a temporary file first so that concurrent readers never observe partial content.
+func (f *File) Put(_ context.Context, record Record) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery %s: %w", record.DeliveryID, err)
+	}
+	tmp, err := os.CreateTemp(f.dir, ".delivery-*")
+	if err != nil {
+		return fmt.Errorf("failed to record delivery %s: %w", record.DeliveryID, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err = tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to record delivery %s: %w", record.DeliveryID, err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to record delivery %s: %w", record.DeliveryID, err)
+	}
+	return os.Rename(tmp.Name(), f.path(record.DeliveryID))
+}
+
+func (f *File) path(deliveryID string) string {
+	return filepath.Join(f.dir, url.PathEscape(deliveryID)+".json")
+}
@@ -0,0 +1,65 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process Store evicting the least recently recorded deliveries beyond its capacity.
+type Memory struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // front is most recently recorded
+}
+
+// NewMemory creates an in-process Store remembering up to maxEntries deliveries for the given ttl.
+// A zero maxEntries or ttl disables the respective bound.
+func NewMemory(maxEntries int, ttl time.Duration) *Memory {
+	return &Memory{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get implements Store.
+func (m *Memory) Get(_ context.Context, deliveryID string) (*Record, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, found := m.entries[deliveryID]
+	if !found {
+		return nil, ErrNotFound
+	}
+	record := elem.Value.(Record)
+	if expired(&record, m.ttl) {
+		m.lru.Remove(elem)
+		delete(m.entries, deliveryID)
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
+
+// Put implements Store.
+func (m *Memory) Put(_ context.Context, record Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, found := m.entries[record.DeliveryID]; found {
+		elem.Value = record
+		m.lru.MoveToFront(elem)
+		return nil
+	}
+	m.entries[record.DeliveryID] = m.lru.PushFront(record)
+	for m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Remove(m.lru.Back()).(Record)
+		delete(m.entries, oldest.DeliveryID)
+	}
+	return nil
+}
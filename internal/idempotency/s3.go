@@ -0,0 +1,67 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/controllers/aws"
+)
+
+// ObjectStore is the subset of the AWS controller used by the S3 store.
+type ObjectStore interface {
+	GetS3Object(ctx context.Context, bucket, key string) ([]byte, error)
+	PutS3ObjectWithKey(ctx context.Context, bucket, key string, body []byte) error
+}
+
+// S3 is a Store persisting one JSON object per delivery in an S3 bucket, shared by every replica.
+type S3 struct {
+	objects ObjectStore
+	bucket  string
+	prefix  string
+	ttl     time.Duration
+}
+
+// NewS3 creates a Store persisting deliveries under the given prefix of the S3 bucket.
+// Records older than ttl are ignored; a zero ttl disables expiry. Stale objects are best removed by a bucket lifecycle rule.
+func NewS3(objects ObjectStore, bucket, prefix string, ttl time.Duration) (*S3, error) {
+	if bucket == "" {
+		return nil, errors.New("missing idempotency store bucket name")
+	}
+	return &S3{objects: objects, bucket: bucket, prefix: prefix, ttl: ttl}, nil
+}
+
+// Get implements Store.
+func (s *S3) Get(ctx context.Context, deliveryID string) (*Record, error) {
+	content, err := s.objects.GetS3Object(ctx, s.bucket, s.key(deliveryID))
+	if err != nil {
+		if errors.Is(err, aws.ErrObjectNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read delivery %s: %w", deliveryID, err)
+	}
+	var record Record
+	if err = json.Unmarshal(content, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode delivery %s: %w", deliveryID, err)
+	}
+	if expired(&record, s.ttl) {
+		return nil, ErrNotFound
+	}
+	return &record, nil
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, record Record) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode delivery %s: %w", record.DeliveryID, err)
+	}
+	return s.objects.PutS3ObjectWithKey(ctx, s.bucket, s.key(record.DeliveryID), content)
+}
+
+func (s *S3) key(deliveryID string) string {
+	return s.prefix + url.PathEscape(deliveryID) + ".json"
+}
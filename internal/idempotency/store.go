@@ -0,0 +1,35 @@
+// Package idempotency provides stores recording the outcome of processed webhook deliveries, keyed by delivery ID.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/models"
+)
+
+// ErrNotFound is returned by Store.Get when no outcome is recorded for the delivery.
+var ErrNotFound = errors.New("delivery not found")
+
+// Record is the outcome recorded for a processed delivery.
+type Record struct {
+	DeliveryID  string          `json:"deliveryId"`
+	EventType   string          `json:"eventType"`
+	Status      string          `json:"status"`
+	Response    models.Response `json:"response"`
+	ProcessedAt time.Time       `json:"processedAt"`
+}
+
+// Store records the outcome of processed deliveries.
+type Store interface {
+	// Get returns the outcome recorded for the delivery, or ErrNotFound.
+	Get(ctx context.Context, deliveryID string) (*Record, error)
+	// Put records the outcome of a delivery, replacing any previous record.
+	Put(ctx context.Context, record Record) error
+}
+
+// expired reports whether the record is older than the ttl. A zero ttl never expires.
+func expired(record *Record, ttl time.Duration) bool {
+	return ttl > 0 && time.Since(record.ProcessedAt) > ttl
+}
@@ -0,0 +1,59 @@
+package idempotency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/idempotency"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	file, err := idempotency.NewFile(t.TempDir(), time.Hour)
+	require.NoError(t, err)
+
+	stores := map[string]idempotency.Store{
+		"memory": idempotency.NewMemory(10, time.Hour),
+		"file":   file,
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			_, err := store.Get(ctx, "a/b")
+			assert.ErrorIs(t, err, idempotency.ErrNotFound)
+
+			record := idempotency.Record{
+				DeliveryID:  "a/b",
+				EventType:   "push",
+				Status:      "success",
+				Response:    models.Response{Body: "Promotion complete", StatusCode: 204},
+				ProcessedAt: time.Now().UTC().Truncate(time.Second),
+			}
+			require.NoError(t, store.Put(ctx, record))
+			got, err := store.Get(ctx, "a/b")
+			require.NoError(t, err)
+			assert.Equal(t, record, *got)
+
+			// Records older than the ttl are ignored
+			record.ProcessedAt = time.Now().Add(-2 * time.Hour)
+			require.NoError(t, store.Put(ctx, record))
+			_, err = store.Get(ctx, "a/b")
+			assert.ErrorIs(t, err, idempotency.ErrNotFound)
+		})
+	}
+
+	t.Run("memory capacity", func(t *testing.T) {
+		ctx := context.Background()
+		store := idempotency.NewMemory(2, 0)
+		for _, id := range []string{"1", "2", "3"} {
+			require.NoError(t, store.Put(ctx, idempotency.Record{DeliveryID: id}))
+		}
+		_, err := store.Get(ctx, "1")
+		assert.ErrorIs(t, err, idempotency.ErrNotFound)
+		_, err = store.Get(ctx, "3")
+		assert.NoError(t, err)
+	})
+}
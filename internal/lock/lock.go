@@ -0,0 +1,72 @@
+// Package lock provides keyed locking used to serialise concurrent promotion work on the same target ref.
+package lock
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Locker acquires exclusive locks on arbitrary keys.
+// Implementations backed by a shared store allow serialising work across multiple replicas.
+type Locker interface {
+	// Lock blocks until the lock on key is acquired or the context is done.
+	// The returned function releases the lock and is safe to call more than once.
+	Lock(ctx context.Context, key string) (unlock func(), err error)
+}
+
+// Key builds a lock key from the given parts, e.g. owner, repository and base ref.
+func Key(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// Memory is an in-process Locker. Keys are only tracked while the lock is held or awaited.
+type Memory struct {
+	mu    sync.Mutex
+	locks map[string]*entry
+}
+
+type entry struct {
+	sem  chan struct{}
+	refs int
+}
+
+// NewMemory creates a new in-process Locker.
+func NewMemory() *Memory {
+	return &Memory{locks: make(map[string]*entry)}
+}
+
+// Lock implements Locker.
+func (m *Memory) Lock(ctx context.Context, key string) (func(), error) {
+	m.mu.Lock()
+	e, found := m.locks[key]
+	if !found {
+		e = &entry{sem: make(chan struct{}, 1)}
+		m.locks[key] = e
+	}
+	e.refs++
+	m.mu.Unlock()
+
+	select {
+	case e.sem <- struct{}{}:
+	case <-ctx.Done():
+		m.release(key, e)
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-e.sem
+			m.release(key, e)
+		})
+	}, nil
+}
+
+func (m *Memory) release(key string, e *entry) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e.refs--; e.refs == 0 {
+		delete(m.locks, key)
+	}
+}
@@ -0,0 +1,47 @@
+package lock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	locker := lock.NewMemory()
+	key := lock.Key("owner", "repo", "staging")
+
+	unlock, err := locker.Lock(context.Background(), key)
+	require.NoError(t, err)
+
+	// Other keys are not affected
+	unlockOther, err := locker.Lock(context.Background(), lock.Key("owner", "repo", "production"))
+	require.NoError(t, err)
+	unlockOther()
+
+	// The same key blocks until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = locker.Lock(ctx, key)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Waiters acquire the lock once released
+	acquired := make(chan struct{})
+	go func() {
+		unlockNext, err := locker.Lock(context.Background(), key)
+		assert.NoError(t, err)
+		close(acquired)
+		unlockNext()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while held")
+	case <-time.After(20 * time.Millisecond):
+	}
+	unlock()
+	unlock() // releasing twice is a no-op
+	<-acquired
+}
@@ -12,4 +12,31 @@ type Response struct {
 	Body       string
 	Headers    map[string]string
 	StatusCode int
+	// Plan holds the GitHub mutations planned in dry-run mode.
+	Plan []PlannedAction
+	// Trace holds the decision of each processor run against the request.
+	Trace []TraceEntry
+}
+
+// TraceEntry records the decision of a single processor.
+type TraceEntry struct {
+	Processor  string  `json:"processor"`
+	Outcome    string  `json:"outcome"`
+	Reason     string  `json:"reason,omitempty"`
+	Message    string  `json:"message,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+	HeadRef    string  `json:"headRef,omitempty"`
+	BaseRef    string  `json:"baseRef,omitempty"`
+	HeadSHA    string  `json:"headSha,omitempty"`
+}
+
+// PlannedAction describes a GitHub mutation recorded instead of being executed in dry-run mode.
+type PlannedAction struct {
+	Action     string         `json:"action"`
+	Owner      string         `json:"owner,omitempty"`
+	Repository string         `json:"repository,omitempty"`
+	Ref        string         `json:"ref,omitempty"`
+	SHA        string         `json:"sha,omitempty"`
+	Details    map[string]any `json:"details,omitempty"`
 }
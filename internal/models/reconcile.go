@@ -0,0 +1,24 @@
+package models
+
+// ReconcileReport summarises a reconciliation run over the installations and repositories of the app.
+type ReconcileReport struct {
+	Installations int                   `json:"installations"`
+	Repositories  int                   `json:"repositories"`
+	Promotions    []ReconciledPromotion `json:"promotions,omitempty"`
+	Errors        []string              `json:"errors,omitempty"`
+}
+
+// ReconciledPromotion records the outcome of the reconciliation of a single stage pair.
+type ReconciledPromotion struct {
+	Repository string `json:"repository"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	HeadSHA    string `json:"headSha"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Plan holds the GitHub mutations planned in dry-run mode.
+	Plan []PlannedAction `json:"plan,omitempty"`
+	// Trace holds the decision of each processor run against the stage pair.
+	Trace []TraceEntry `json:"trace,omitempty"`
+}
@@ -0,0 +1,97 @@
+package promotion
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+)
+
+// Approval is the outcome of the evaluation of the reviews of a promotion request against a review policy.
+type Approval struct {
+	// Approvers lists the reviewers whose latest review approves the head SHA, or a previous commit unless stale
+	// approvals are dismissed.
+	Approvers []string
+	// MissingApprovals is the number of approvals still required.
+	MissingApprovals int
+	// MissingTeams lists the required teams with no approving member.
+	MissingTeams []string
+	// ChangesRequested lists the reviewers whose latest review requests changes, unless allowed by the policy.
+	ChangesRequested []string
+}
+
+// Approved reports whether the reviews satisfy the review policy.
+func (a Approval) Approved() bool {
+	return a.MissingApprovals == 0 && len(a.MissingTeams) == 0 && len(a.ChangesRequested) == 0
+}
+
+// String summarises the unsatisfied requirements of the review policy, e.g. "missing approvals: 1; changes requested: bob".
+func (a Approval) String() string {
+	var parts []string
+	if a.MissingApprovals > 0 {
+		parts = append(parts, fmt.Sprintf("missing approvals: %d", a.MissingApprovals))
+	}
+	if len(a.MissingTeams) > 0 {
+		parts = append(parts, fmt.Sprintf("missing team approvals: %s", strings.Join(a.MissingTeams, ", ")))
+	}
+	if len(a.ChangesRequested) > 0 {
+		parts = append(parts, fmt.Sprintf("changes requested: %s", strings.Join(a.ChangesRequested, ", ")))
+	}
+	if len(parts) == 0 {
+		return "review policy satisfied"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// EvaluateReviews evaluates the reviews of a promotion request against the review policy. Only the latest approving,
+// change-requesting or dismissed review of each reviewer is considered, as GitHub does. The team members map each
+// required team to the logins of its members.
+func EvaluateReviews(policy config.ReviewPolicy, headSHA string, reviews []*github.PullRequestReview, teamMembers map[string][]string) Approval {
+	latest := make(map[string]*github.PullRequestReview, len(reviews))
+	var reviewers []string
+	for _, review := range reviews {
+		switch review.GetState() {
+		case "APPROVED", "CHANGES_REQUESTED", "DISMISSED":
+		default:
+			// Comments and pending reviews do not change the decision of the reviewer
+			continue
+		}
+		login := strings.ToLower(review.GetUser().GetLogin())
+		current, found := latest[login]
+		if !found {
+			reviewers = append(reviewers, login)
+		}
+		if !found || !review.GetSubmittedAt().Before(current.GetSubmittedAt().Time) {
+			latest[login] = review
+		}
+	}
+
+	var approval Approval
+	for _, login := range reviewers {
+		review := latest[login]
+		switch review.GetState() {
+		case "APPROVED":
+			if policy.DismissStale && review.GetCommitID() != headSHA {
+				continue
+			}
+			approval.Approvers = append(approval.Approvers, review.GetUser().GetLogin())
+		case "CHANGES_REQUESTED":
+			if !policy.AllowChangesRequested {
+				approval.ChangesRequested = append(approval.ChangesRequested, review.GetUser().GetLogin())
+			}
+		}
+	}
+
+	approval.MissingApprovals = max(policy.MinApprovals-len(approval.Approvers), 0)
+	for _, team := range policy.RequiredTeams {
+		members := teamMembers[team]
+		if !slices.ContainsFunc(approval.Approvers, func(approver string) bool {
+			return slices.ContainsFunc(members, func(member string) bool { return strings.EqualFold(member, approver) })
+		}) {
+			approval.MissingTeams = append(approval.MissingTeams, team)
+		}
+	}
+	return approval
+}
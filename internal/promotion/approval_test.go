@@ -0,0 +1,86 @@
+package promotion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateReviews(t *testing.T) {
+	review := func(login, state, commitID string, submitted int) *github.PullRequestReview {
+		return &github.PullRequestReview{
+			User:        &github.User{Login: new(login)},
+			State:       new(state),
+			CommitID:    new(commitID),
+			SubmittedAt: &github.Timestamp{Time: time.Date(2026, 1, 1, 0, submitted, 0, 0, time.UTC)},
+		}
+	}
+	teams := map[string][]string{"sre": {"Carol"}, "my-org/security": {"dave"}}
+
+	tests := []struct {
+		name    string
+		policy  config.ReviewPolicy
+		reviews []*github.PullRequestReview
+		want    promotion.Approval
+	}{
+		{
+			name:    "single approval",
+			policy:  config.ReviewPolicy{MinApprovals: 1},
+			reviews: []*github.PullRequestReview{review("alice", "APPROVED", "head", 1)},
+			want:    promotion.Approval{Approvers: []string{"alice"}},
+		},
+		{
+			name:   "comments do not override approvals",
+			policy: config.ReviewPolicy{MinApprovals: 2},
+			reviews: []*github.PullRequestReview{
+				review("alice", "APPROVED", "head", 1),
+				review("alice", "COMMENTED", "head", 2),
+				review("bob", "APPROVED", "head", 1),
+				review("bob", "DISMISSED", "head", 3),
+			},
+			want: promotion.Approval{Approvers: []string{"alice"}, MissingApprovals: 1},
+		},
+		{
+			name:    "outstanding changes requested",
+			policy:  config.ReviewPolicy{MinApprovals: 1},
+			reviews: []*github.PullRequestReview{review("alice", "APPROVED", "head", 1), review("bob", "CHANGES_REQUESTED", "head", 2)},
+			want:    promotion.Approval{Approvers: []string{"alice"}, ChangesRequested: []string{"bob"}},
+		},
+		{
+			name:    "changes requested allowed",
+			policy:  config.ReviewPolicy{MinApprovals: 1, AllowChangesRequested: true},
+			reviews: []*github.PullRequestReview{review("alice", "APPROVED", "head", 1), review("bob", "CHANGES_REQUESTED", "head", 2)},
+			want:    promotion.Approval{Approvers: []string{"alice"}},
+		},
+		{
+			name:    "stale approvals dismissed",
+			policy:  config.ReviewPolicy{MinApprovals: 2, DismissStale: true},
+			reviews: []*github.PullRequestReview{review("alice", "APPROVED", "previous", 1), review("bob", "APPROVED", "head", 2)},
+			want:    promotion.Approval{Approvers: []string{"bob"}, MissingApprovals: 1},
+		},
+		{
+			name:    "required teams",
+			policy:  config.ReviewPolicy{MinApprovals: 1, RequiredTeams: []string{"sre", "my-org/security"}},
+			reviews: []*github.PullRequestReview{review("carol", "APPROVED", "head", 1)},
+			want:    promotion.Approval{Approvers: []string{"carol"}, MissingTeams: []string{"my-org/security"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, promotion.EvaluateReviews(tt.policy, "head", tt.reviews, teams))
+		})
+	}
+}
+
+func TestApprovalString(t *testing.T) {
+	assert.True(t, promotion.Approval{Approvers: []string{"alice"}}.Approved())
+	assert.Equal(t, "review policy satisfied", promotion.Approval{}.String())
+
+	approval := promotion.Approval{MissingApprovals: 1, MissingTeams: []string{"sre"}, ChangesRequested: []string{"bob"}}
+	assert.False(t, approval.Approved())
+	assert.Equal(t, "missing approvals: 1; missing team approvals: sre; changes requested: bob", approval.String())
+}
@@ -0,0 +1,60 @@
+package promotion
+
+import (
+	"slices"
+	"strings"
+)
+
+// ChatOps commands accepted in comments on promotion requests.
+const (
+	// CommandPromote re-evaluates the promotion request and fast-forwards it if it is ready.
+	CommandPromote = "promote"
+	// CommandHold prevents the promotion request from being promoted until it is released.
+	CommandHold = "hold"
+	// CommandUnhold releases a held promotion request and re-evaluates it.
+	CommandUnhold = "unhold"
+	// CommandExplain evaluates the promotion request without promoting it and replies with the decision trace.
+	CommandExplain = "explain"
+	// CommandRetryFeedback sends the promotion feedback of the promotion request again.
+	CommandRetryFeedback = "retry-feedback"
+)
+
+var commands = []string{CommandPromote, CommandHold, CommandUnhold, CommandExplain, CommandRetryFeedback}
+
+// Command is a ChatOps command issued in a comment on a promotion request.
+type Command struct {
+	// Name is the command, e.g. CommandPromote.
+	Name string
+	// CommentID is the ID of the comment issuing the command.
+	CommentID int64
+	// Number is the number of the promotion request.
+	Number int
+	// Actor is the login of the commenter.
+	Actor string
+}
+
+// ParseCommand returns the command issued by the first line of the comment body, if it is a known slash command.
+func ParseCommand(body string) (string, bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(body), "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", false
+	}
+	name := strings.ToLower(strings.TrimPrefix(fields[0], "/"))
+	return name, slices.Contains(commands, name)
+}
+
+// roles lists the repository roles by increasing privilege.
+var roles = []string{"read", "triage", "write", "maintain", "admin"}
+
+// IsRole reports whether the role is one of the base repository roles. (read, triage, write, maintain, admin)
+func IsRole(role string) bool {
+	return slices.Contains(roles, strings.ToLower(role))
+}
+
+// HasRole reports whether the repository role grants at least the privileges of the minimum role.
+// Unknown roles, e.g. custom roles, are granted no privilege, and unknown minimum roles are granted to no role.
+func HasRole(role, minRole string) bool {
+	rank, minRank := slices.Index(roles, strings.ToLower(role)), slices.Index(roles, strings.ToLower(minRole))
+	return rank >= 0 && minRank >= 0 && rank >= minRank
+}
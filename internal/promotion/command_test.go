@@ -0,0 +1,41 @@
+package promotion_test
+
+import (
+	"testing"
+
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		body   string
+		want   string
+		wantOk bool
+	}{
+		{body: "/promote", want: promotion.CommandPromote, wantOk: true},
+		{body: "  /Hold please\nwaiting on QA", want: promotion.CommandHold, wantOk: true},
+		{body: "/retry-feedback", want: promotion.CommandRetryFeedback, wantOk: true},
+		{body: "/deploy", want: "deploy"},
+		{body: "LGTM\n/promote"},
+		{body: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			got, ok := promotion.ParseCommand(tt.body)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	assert.True(t, promotion.HasRole("admin", "write"))
+	assert.True(t, promotion.HasRole("write", "write"))
+	assert.True(t, promotion.HasRole("Maintain", "triage"))
+	assert.False(t, promotion.HasRole("triage", "write"))
+	assert.False(t, promotion.HasRole("read", "write"))
+	assert.False(t, promotion.HasRole("custom-role", "read"))
+	assert.False(t, promotion.HasRole("admin", "maintainer"))
+	assert.False(t, promotion.HasRole("admin", ""))
+}
@@ -2,12 +2,14 @@
 package promotion
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 
 	"github.com/google/go-github/v88/github"
 	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/lock"
 	"github.com/isometry/gh-promotion-app/internal/models"
 	"github.com/shurcooL/githubv4"
 )
@@ -21,11 +23,80 @@ type Bus struct {
 	Event       any
 	EventStatus EventStatus
 
-	Body    []byte
-	Headers map[string]string
-	Error   error
+	DeliveryID string
+	Body       []byte
+	Headers    map[string]string
+	Error      error
 
 	Repository *models.RepositoryContext
+
+	// Plan collects the GitHub mutations planned in dry-run mode. It is nil if mutations are executed.
+	Plan *Plan
+	// Command is the ChatOps command being processed. It is nil for events other than command comments.
+	Command *Command
+	// Resolved is set by event processors settling the outcome of the event themselves, e.g. for merge groups,
+	// so that post-processors leave the promotion untouched.
+	Resolved bool
+	// Trace records the decision of each processor run against the bus.
+	Trace []models.TraceEntry
+	skip  *skipDecision
+	// Forks holds the buses of the other promotions of an event promoting into several stages, e.g. a push to a stage
+	// fanning out, each run through the post-processors and feedback after the bus.
+	Forks  []*Bus
+	forked bool
+
+	// Locker serialises the processing of events targeting the same base ref. Locking is disabled if nil.
+	Locker lock.Locker
+	unlock func()
+}
+
+// LockBaseRef acquires the lock on the owner/repository/base ref of the bus, held until Unlock is called.
+// It is a no-op if no Locker is set, the base ref is not yet known, or the lock is already held.
+func (b *Bus) LockBaseRef(ctx context.Context) error {
+	if b.Locker == nil || b.unlock != nil || b.Context == nil || b.Context.BaseRef == nil {
+		return nil
+	}
+	key := lock.Key(helpers.String(b.Context.Owner), helpers.String(b.Context.Repository), helpers.NormaliseRef(b.Context.BaseRef))
+	unlock, err := b.Locker.Lock(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock on %s: %w", key, err)
+	}
+	b.unlock = unlock
+	return nil
+}
+
+// Fork returns a copy of the bus for another promotion of the same event, to be set up by the caller and recorded in
+// Forks. The fork carries no pull request, ChatOps command, decision trace or lock.
+func (b *Bus) Fork() *Bus {
+	pCtx := *b.Context
+	pCtx.PullRequest = nil
+	pCtx.Commits = nil
+	return &Bus{
+		Context:     &pCtx,
+		EventType:   b.EventType,
+		Event:       b.Event,
+		EventStatus: b.EventStatus,
+		DeliveryID:  b.DeliveryID,
+		Body:        b.Body,
+		Headers:     b.Headers,
+		Repository:  b.Repository,
+		Plan:        b.Plan,
+		Locker:      b.Locker,
+		forked:      true,
+	}
+}
+
+// IsFork reports whether the bus was forked from the bus of the event, e.g. so that the event is archived only once.
+func (b *Bus) IsFork() bool {
+	return b.forked
+}
+
+// Unlock releases the lock acquired by LockBaseRef, if any.
+func (b *Bus) Unlock() {
+	if b.unlock != nil {
+		b.unlock()
+		b.unlock = nil
+	}
 }
 
 // EventStatus represents the status of a promotion event, which can be one of success, failure, or pending.
@@ -1,11 +1,17 @@
 package promotion
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/pkg/errors"
 )
 
+// ErrBudgetExhausted is returned when the request deadline or a phase timeout expires before processing completes.
+// Callers should answer with a retryable 5xx status code.
+var ErrBudgetExhausted = errors.New("processing budget exhausted")
+
 // InternalError represents an error type with an underlying cause, used to encapsulate failures during processing.
 type InternalError struct {
 	Cause error
@@ -15,6 +21,11 @@ func (m *InternalError) Error() string {
 	return fmt.Sprintf("internal promotion error: %v", m.Cause)
 }
 
+// Unwrap returns the underlying cause of the error.
+func (m *InternalError) Unwrap() error {
+	return m.Cause
+}
+
 // NewInternalErrorf creates and returns a new `InternalError` with a formatted error message as its cause.
 func NewInternalErrorf(format string, args ...any) error {
 	return &InternalError{Cause: errors.Errorf(format, args...)}
@@ -24,3 +35,105 @@ func NewInternalErrorf(format string, args ...any) error {
 func NewInternalError(message string) error {
 	return &InternalError{Cause: errors.New(message)}
 }
+
+// ErrorKind classifies failures by the HTTP status they are answered with and whether they are worth retrying.
+type ErrorKind string
+
+const (
+	// KindInvalidInput is a permanent failure caused by the request itself, e.g. an unsupported event or malformed payload.
+	KindInvalidInput ErrorKind = "invalid-input"
+	// KindAuth is a permanent failure to authenticate the request or against upstream APIs.
+	KindAuth ErrorKind = "auth"
+	// KindConflict is a permanent failure caused by the repository state, e.g. a target ref that cannot be fast-forwarded.
+	KindConflict ErrorKind = "conflict"
+	// KindTransient is a temporary upstream failure, e.g. rate limiting, throttling or a 5xx response.
+	KindTransient ErrorKind = "transient"
+	// KindInternal is an unexpected failure. It is assumed to be retryable.
+	KindInternal ErrorKind = "internal"
+)
+
+// StatusCode returns the HTTP status code a failure of this kind is answered with.
+func (k ErrorKind) StatusCode() int {
+	switch k {
+	case KindInvalidInput:
+		return http.StatusUnprocessableEntity
+	case KindAuth:
+		return http.StatusUnauthorized
+	case KindConflict:
+		return http.StatusConflict
+	case KindTransient:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Retryable reports whether a failure of this kind may succeed once redelivered.
+func (k ErrorKind) Retryable() bool {
+	return k == KindTransient || k == KindInternal
+}
+
+// ClassifiedError is an error of a known kind. Its kind determines the HTTP status code and retry semantics of the failure.
+type ClassifiedError struct {
+	Kind ErrorKind
+	// Status overrides the status code of the kind, e.g. 403 rather than 401 for an invalid signature. Optional.
+	Status int
+	Cause  error
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Cause.Error()
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ClassifiedError) Unwrap() error {
+	return e.Cause
+}
+
+// NewError classifies the given error. A nil error yields nil.
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Kind: kind, Cause: err}
+}
+
+// NewErrorf creates a classified error with a formatted message as its cause.
+func NewErrorf(kind ErrorKind, format string, args ...any) error {
+	return &ClassifiedError{Kind: kind, Cause: errors.Errorf(format, args...)}
+}
+
+// WithStatus overrides the HTTP status code of a classified error. Unclassified errors are classified as internal.
+func WithStatus(err error, status int) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Kind: Classify(err), Status: status, Cause: err}
+}
+
+// Classify returns the kind of the given error. Unclassified errors are internal, unless the processing budget ran out.
+func Classify(err error) ErrorKind {
+	var classified *ClassifiedError
+	switch {
+	case errors.As(err, &classified):
+		return classified.Kind
+	case errors.Is(err, ErrBudgetExhausted), errors.Is(err, context.DeadlineExceeded):
+		return KindTransient
+	default:
+		return KindInternal
+	}
+}
+
+// StatusCode returns the HTTP status code the given error is answered with.
+func StatusCode(err error) int {
+	var classified *ClassifiedError
+	if errors.As(err, &classified) && classified.Status != 0 {
+		return classified.Status
+	}
+	return Classify(err).StatusCode()
+}
+
+// IsRetryable reports whether the given error may succeed once redelivered.
+func IsRetryable(err error) bool {
+	return Classify(err).Retryable()
+}
@@ -0,0 +1,72 @@
+package promotion_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name      string
+		err       error
+		kind      promotion.ErrorKind
+		status    int
+		retryable bool
+	}{
+		{
+			name:   "invalid input",
+			err:    promotion.NewErrorf(promotion.KindInvalidInput, "missing event type"),
+			kind:   promotion.KindInvalidInput,
+			status: http.StatusUnprocessableEntity,
+		},
+		{
+			name:   "wrapped conflict",
+			err:    errors.Wrap(fmt.Errorf("post phase: %w", promotion.NewErrorf(promotion.KindConflict, "not a fast forward")), "failed"),
+			kind:   promotion.KindConflict,
+			status: http.StatusConflict,
+		},
+		{
+			name:   "status override",
+			err:    promotion.WithStatus(promotion.NewErrorf(promotion.KindAuth, "invalid signature"), http.StatusForbidden),
+			kind:   promotion.KindAuth,
+			status: http.StatusForbidden,
+		},
+		{
+			name:      "transient",
+			err:       promotion.NewError(promotion.KindTransient, errors.New("rate limited")),
+			kind:      promotion.KindTransient,
+			status:    http.StatusServiceUnavailable,
+			retryable: true,
+		},
+		{
+			name:      "budget exhausted",
+			err:       fmt.Errorf("event phase: %w: %w", promotion.ErrBudgetExhausted, context.DeadlineExceeded),
+			kind:      promotion.KindTransient,
+			status:    http.StatusServiceUnavailable,
+			retryable: true,
+		},
+		{
+			name:      "unclassified",
+			err:       promotion.NewInternalError("boom"),
+			kind:      promotion.KindInternal,
+			status:    http.StatusInternalServerError,
+			retryable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.kind, promotion.Classify(tc.err))
+			assert.Equal(t, tc.status, promotion.StatusCode(tc.err))
+			assert.Equal(t, tc.retryable, promotion.IsRetryable(tc.err))
+		})
+	}
+
+	assert.NoError(t, promotion.NewError(promotion.KindTransient, nil))
+}
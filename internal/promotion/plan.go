@@ -0,0 +1,45 @@
+package promotion
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/isometry/gh-promotion-app/internal/models"
+)
+
+// Plan collects the GitHub mutations recorded instead of being executed in dry-run mode. It is safe for concurrent use.
+type Plan struct {
+	mu      sync.Mutex
+	actions []models.PlannedAction
+}
+
+// Record appends a planned action to the plan.
+func (p *Plan) Record(action models.PlannedAction) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.actions = append(p.actions, action)
+}
+
+// Actions returns the planned actions in the order they were recorded.
+func (p *Plan) Actions() []models.PlannedAction {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.actions)
+}
+
+type planContextKey struct{}
+
+// ContextWithPlan returns a context carrying the given dry-run plan.
+func ContextWithPlan(ctx context.Context, plan *Plan) context.Context {
+	return context.WithValue(ctx, planContextKey{}, plan)
+}
+
+// PlanFromContext returns the dry-run plan carried by the context, or nil if mutations must be executed.
+func PlanFromContext(ctx context.Context) *Plan {
+	plan, _ := ctx.Value(planContextKey{}).(*Plan)
+	return plan
+}
@@ -1,6 +1,7 @@
 package promotion
 
 import (
+	"fmt"
 	"log/slog"
 	"slices"
 	"strings"
@@ -18,53 +19,190 @@ const (
 	defaultClass = "static"
 )
 
-// Promoter is a struct that holds the promotion stages.
+// Promoter is a struct that holds the promotion graph: the promotion stages and the stages each of them promotes into.
+// A linear promotion path is a graph in which every stage promotes into the next one.
 type Promoter struct {
-	Class  string
+	Class string
+	// Stages lists the promotion stages in topological order: every stage follows the stages promoting into it.
 	Stages []string
+	// Targets maps each stage to the stages it promotes into, in definition order. Stages promoting into several
+	// stages fan out, and stages promoted into from several stages are joins.
+	Targets map[string][]string
 }
 
-// _defaultPromoter is NewDefaultPromoter instance cached at runtime.
-var _defaultPromoter = NewDefaultPromoter()
+// StagePair is the promotion of a source stage into a target stage, i.e. an edge of the promotion graph.
+type StagePair struct {
+	Source, Target string
+}
 
-// NewDefaultPromoter creates a new default promoter instance.
-func NewDefaultPromoter() *Promoter {
+// NewDefaultPromoter creates a new default promoter instance from config.Promotion.DefaultGraph if set, else from
+// config.Promotion.DefaultStages.
+func NewDefaultPromoter(logger *slog.Logger) *Promoter {
+	if config.Promotion.DefaultGraph != "" {
+		promoter, err := NewGraphPromoter(defaultClass, config.Promotion.DefaultGraph)
+		if err == nil {
+			return promoter
+		}
+		// Unreachable through the handler, which rejects invalid default graphs upfront
+		logger.Error("invalid default promotion graph. Defaulting to default stages...", slog.String("graph", config.Promotion.DefaultGraph), slog.Any("error", err))
+	}
 	return NewStagePromoter(defaultClass, config.Promotion.DefaultStages)
 }
 
-// NewStagePromoter creates a new promoter instance with the given stages.
+// NewStagePromoter creates a new promoter instance with the given stages, each promoting into the next one.
 func NewStagePromoter(class string, stages []string) *Promoter {
-	return &Promoter{Class: class, Stages: stages}
+	targets := make(map[string][]string, len(stages))
+	for i := 0; i < len(stages)-1; i++ {
+		targets[stages[i]] = append(targets[stages[i]], stages[i+1])
+	}
+	return &Promoter{Class: class, Stages: stages, Targets: targets}
+}
+
+// NewGraphPromoter creates a new promoter instance from a promotion graph definition. The definition lists chains
+// separated by ";", each listing levels separated by ">" (or "→"), each listing stages separated by ",": every stage of
+// a level promotes into every stage of the next level. For example, "main > staging > canary-eu, canary-us > production"
+// fans out of staging into both canaries, which join into production. A definition without ">" is a comma-separated
+// list of stages, each promoting into the next one. Cycles are rejected.
+func NewGraphPromoter(class, definition string) (*Promoter, error) {
+	definition = strings.ReplaceAll(definition, "→", ">")
+	if !IsGraphDefinition(definition) {
+		stages := splitStages(definition)
+		if len(stages) == 0 {
+			return nil, NewErrorf(KindInvalidInput, "promotion graph %q defines no stage", definition)
+		}
+		return NewStagePromoter(class, stages), nil
+	}
+
+	var stages []string
+	targets := make(map[string][]string)
+	for _, chain := range strings.Split(definition, ";") {
+		if strings.TrimSpace(chain) == "" {
+			continue
+		}
+		var sources []string
+		for _, level := range strings.Split(chain, ">") {
+			levelStages := splitStages(level)
+			if len(levelStages) == 0 {
+				return nil, NewErrorf(KindInvalidInput, "promotion graph chain %q has an empty level", strings.TrimSpace(chain))
+			}
+			for _, stage := range levelStages {
+				if !slices.Contains(stages, stage) {
+					stages = append(stages, stage)
+				}
+			}
+			for _, source := range sources {
+				for _, target := range levelStages {
+					if !slices.Contains(targets[source], target) {
+						targets[source] = append(targets[source], target)
+					}
+				}
+			}
+			sources = levelStages
+		}
+	}
+
+	ordered, err := topologicalOrder(stages, targets)
+	if err != nil {
+		return nil, err
+	}
+	return &Promoter{Class: class, Stages: ordered, Targets: targets}, nil
+}
+
+// IsGraphDefinition reports whether the promotion path definition is a graph rather than a comma-separated list of stages.
+func IsGraphDefinition(definition string) bool {
+	return strings.ContainsAny(definition, ">→")
+}
+
+// splitStages splits a comma-separated list of stages, ignoring blank entries.
+func splitStages(list string) []string {
+	stages := strings.Split(list, ",")
+	for i, stage := range stages {
+		stages[i] = strings.TrimSpace(stage)
+	}
+	return slices.DeleteFunc(stages, func(s string) bool { return s == "" })
+}
+
+// topologicalOrder orders the stages so that every stage follows the stages promoting into it, otherwise preserving
+// the given order. It fails if the promotion graph has a cycle.
+func topologicalOrder(stages []string, targets map[string][]string) ([]string, error) {
+	sources := make(map[string]int, len(stages))
+	for _, stageTargets := range targets {
+		for _, target := range stageTargets {
+			sources[target]++
+		}
+	}
+
+	ordered := make([]string, 0, len(stages))
+	remaining := slices.Clone(stages)
+	for len(remaining) > 0 {
+		next := slices.IndexFunc(remaining, func(stage string) bool { return sources[stage] == 0 })
+		if next == -1 {
+			return nil, NewErrorf(KindInvalidInput, "promotion graph has a cycle through %s", strings.Join(remaining, ", "))
+		}
+		stage := remaining[next]
+		remaining = slices.Delete(remaining, next, next+1)
+		ordered = append(ordered, stage)
+		for _, target := range targets[stage] {
+			sources[target]--
+		}
+	}
+	return ordered, nil
 }
 
 // NewDynamicPromoter creates a new promoter instance from a repository's custom
 // properties. The promoter-path property may be either a multi_select list (whose
-// entries are used directly as ordered stages) or a single string of
-// comma-separated stages (retained for backward compatibility).
+// entries are used directly as ordered stages, or as chains of a promotion graph)
+// or a single string of comma-separated stages (retained for backward compatibility)
+// or of a promotion graph definition (see NewGraphPromoter).
 func NewDynamicPromoter(logger *slog.Logger, props map[string]any, promoterKey, promoterClassKey string) *Promoter {
 	raw, found := props[promoterKey]
 	if !found {
 		logger.Warn("promoter key not found in properties. Defaulting to standard promoter...", slog.Any("key", promoterKey))
-		return _defaultPromoter
+		return NewDefaultPromoter(logger)
 	}
 
-	var stages []string
+	class := defaultClass
+	if classValue := helpers.GetCustomProperty[string](props, promoterClassKey); classValue != "" {
+		class = classValue
+	}
+
+	var (
+		stages     []string
+		definition string
+	)
 	switch raw.(type) {
 	case []string, []any:
-		// multi_select: entries are the ordered stages.
+		// multi_select: entries are the ordered stages, or the chains of a promotion graph.
 		stages = helpers.GetCustomProperty[[]string](props, promoterKey)
+		if slices.ContainsFunc(stages, IsGraphDefinition) {
+			definition = strings.Join(stages, ";")
+		}
 	default:
-		// single value: comma-separated stages.
+		// single value: comma-separated stages, or a promotion graph.
 		stagesBlob := strings.TrimSpace(helpers.GetCustomProperty[string](props, promoterKey))
 		if stagesBlob == "" {
 			logger.Warn("promoter key found but empty. Defaulting to standard promoter...", slog.Any("key", promoterKey))
-			return _defaultPromoter
+			return NewDefaultPromoter(logger)
 		}
 		if strings.HasSuffix(stagesBlob, ",") {
 			logger.Warn("promoter key found but trailing comma found. Removing...", slog.Any("key", promoterKey))
 			stagesBlob = strings.TrimSuffix(stagesBlob, ",")
 		}
-		stages = strings.Split(stagesBlob, ",")
+		if IsGraphDefinition(stagesBlob) {
+			definition = stagesBlob
+		} else {
+			stages = strings.Split(stagesBlob, ",")
+		}
+	}
+
+	if definition != "" {
+		promoter, err := NewGraphPromoter(class, definition)
+		if err != nil {
+			logger.Warn("promoter key found but the promotion graph is invalid. Defaulting to standard promoter...", slog.Any("key", promoterKey), slog.Any("error", err))
+			return NewDefaultPromoter(logger)
+		}
+		logger.Debug("dynamic promoter graph loaded...", slog.Any("stages", promoter.Stages), slog.Any("targets", promoter.Targets))
+		return promoter
 	}
 
 	for i, stage := range stages {
@@ -73,14 +211,10 @@ func NewDynamicPromoter(logger *slog.Logger, props map[string]any, promoterKey,
 	stages = slices.DeleteFunc(stages, func(s string) bool { return s == "" })
 	if len(stages) == 0 {
 		logger.Warn("promoter key found but no stages were defined. Defaulting to standard promoter...", slog.Any("key", promoterKey))
-		return _defaultPromoter
+		return NewDefaultPromoter(logger)
 	}
 
 	logger.Debug("dynamic promoter stages loaded...", slog.Any("stages", stages))
-	class := defaultClass
-	if classValue := helpers.GetCustomProperty[string](props, promoterClassKey); classValue != "" {
-		class = classValue
-	}
 	return NewStagePromoter(class, stages)
 }
 
@@ -91,47 +225,120 @@ func (sp *Promoter) StageIndex(ref string) int {
 	return slices.Index(sp.Stages, helpers.NormaliseRef(ref))
 }
 
-// IsPromotionRequest checks if the given pull request is a promotion request.
-func (sp *Promoter) IsPromotionRequest(pr *github.PullRequest) bool {
-	// ensure p.HeadRef and baseRef are contiguous promotion Stages, and that the head ref is not the last stage
-	if headIndex := sp.StageIndex(*pr.Head.Ref); headIndex != -1 && headIndex < len(sp.Stages)-1 {
-		return sp.Stages[headIndex+1] == helpers.NormaliseRef(*pr.Base.Ref)
+// NextStages returns the stages the given ref promotes into. Stages fanning out promote into several stages.
+func (sp *Promoter) NextStages(ref string) []string {
+	return sp.Targets[helpers.NormaliseRef(ref)]
+}
+
+// PreviousStages returns the stages promoting into the given ref, in the order of Stages. Join stages are promoted
+// into from several stages.
+func (sp *Promoter) PreviousStages(ref string) []string {
+	stage := helpers.NormaliseRef(ref)
+	var previous []string
+	for _, source := range sp.Stages {
+		if slices.Contains(sp.Targets[source], stage) {
+			previous = append(previous, source)
+		}
+	}
+	return previous
+}
+
+// Pairs returns the stage pairs of the promotion graph, ordered by source stage as in Stages.
+func (sp *Promoter) Pairs() []StagePair {
+	var pairs []StagePair
+	for _, source := range sp.Stages {
+		for _, target := range sp.Targets[source] {
+			pairs = append(pairs, StagePair{Source: source, Target: target})
+		}
 	}
+	return pairs
+}
+
+// Level returns the length of the longest promotion path leading to the given ref, i.e. 0 for the first stages.
+// -1 indicates that the ref is not a promotion stage.
+func (sp *Promoter) Level(ref string) int {
+	level, found := sp.levels()[helpers.NormaliseRef(ref)]
+	if !found {
+		return -1
+	}
+	return level
+}
 
-	return false
+// Levels returns the number of levels of the promotion graph, i.e. the number of stages of a linear promotion path.
+func (sp *Promoter) Levels() int {
+	var levels int
+	for _, level := range sp.levels() {
+		levels = max(levels, level+1)
+	}
+	return levels
+}
+
+// levels maps each stage to its level, resolved in topological order.
+func (sp *Promoter) levels() map[string]int {
+	levels := make(map[string]int, len(sp.Stages))
+	for _, stage := range sp.Stages {
+		if _, found := levels[stage]; !found {
+			levels[stage] = 0
+		}
+		for _, target := range sp.Targets[stage] {
+			levels[target] = max(levels[target], levels[stage]+1)
+		}
+	}
+	return levels
 }
 
-// IsPromotableRef checks if the given ref is promotable.
-func (sp *Promoter) IsPromotableRef(ref string) (string, bool) {
-	if headIndex := sp.StageIndex(ref); headIndex != -1 && headIndex < len(sp.Stages)-1 {
-		return sp.Stages[headIndex+1], true
+// String returns the definition of the promotion graph: the comma-separated stages of a linear promotion path, else
+// the stages each stage promotes into, as chains separated by ";".
+func (sp *Promoter) String() string {
+	if slices.Equal(sp.Pairs(), NewStagePromoter(sp.Class, sp.Stages).Pairs()) {
+		return strings.Join(sp.Stages, ",")
 	}
+	chains := make([]string, 0, len(sp.Targets))
+	for _, source := range sp.Stages {
+		if targets := sp.Targets[source]; len(targets) > 0 {
+			chains = append(chains, fmt.Sprintf("%s>%s", source, strings.Join(targets, ",")))
+		}
+	}
+	return strings.Join(chains, ";")
+}
+
+// IsPromotionRequest checks if the given pull request is a promotion request.
+func (sp *Promoter) IsPromotionRequest(pr *github.PullRequest) bool {
+	// ensure the base ref is one of the stages the head ref promotes into
+	return slices.Contains(sp.NextStages(*pr.Head.Ref), helpers.NormaliseRef(*pr.Base.Ref))
+}
 
-	return "", false
+// IsPromotableRef checks if the given ref is promotable, returning the stages it promotes into.
+// Stages fanning out promote into several stages.
+func (sp *Promoter) IsPromotableRef(ref string) ([]string, bool) {
+	next := sp.NextStages(ref)
+	return next, len(next) > 0
 }
 
 //go:embed templates/mermaid.md.tmpl
 var mermaidTemplate string
 
-// Mermaid returns a mermaid graph representation of the promotion stages.
+// Mermaid returns a mermaid graph representation of the promotion graph, highlighting the promotion of the pull request.
 func (sp *Promoter) Mermaid(pr *github.PullRequest, commits []*github.RepositoryCommit, promotionError error) (string, error) {
-	if pr == nil || pr.Head == nil || pr.Head.Ref == nil {
-		return "", NewInternalError("pull request head ref is nil")
+	if pr == nil || pr.Head == nil || pr.Head.Ref == nil || pr.Base == nil || pr.Base.Ref == nil {
+		return "", NewInternalError("pull request head or base ref is nil")
 	}
-	index := sp.StageIndex(helpers.NormaliseRef(*pr.Head.Ref))
-	if index == -1 || index == len(sp.Stages)-1 {
-		return "", NewInternalErrorf("base ref must be one of %v", sp.Stages[:len(sp.Stages)-1])
+	if !sp.IsPromotionRequest(pr) {
+		return "", NewInternalErrorf("%s → %s must be one of %v", *pr.Head.Ref, *pr.Base.Ref, sp.Pairs())
 	}
+	promoted := StagePair{Source: helpers.NormaliseRef(*pr.Head.Ref), Target: helpers.NormaliseRef(*pr.Base.Ref)}
 
 	data := struct {
-		Stages         []string
-		PromotionIndex int
-		Commits        []*github.RepositoryCommit
-		PullRequest    *github.PullRequest
-		Error          error
+		Stages      []string
+		Pairs       []StagePair
+		Promotion   StagePair
+		Commits     []*github.RepositoryCommit
+		PullRequest *github.PullRequest
+		Error       error
 	}{
 		sp.Stages,
-		index,
+		slices.DeleteFunc(sp.Pairs(), func(pair StagePair) bool { return pair == promoted }),
+		promoted,
 		commits,
 		pr,
 		promotionError,
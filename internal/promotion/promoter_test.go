@@ -2,6 +2,8 @@
 package promotion_test
 
 import (
+	"bytes"
+	"log/slog"
 	"testing"
 
 	"github.com/google/go-github/v88/github"
@@ -9,6 +11,7 @@ import (
 	"github.com/isometry/gh-promotion-app/internal/helpers"
 	"github.com/isometry/gh-promotion-app/internal/promotion"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStageIndex(t *testing.T) {
@@ -106,37 +109,37 @@ func TestIsPromotableRef(t *testing.T) {
 	testCases := []struct {
 		Name           string
 		Ref            string
-		ExpectedStage  string
+		ExpectedStages []string
 		ExpectedResult bool
 	}{
 		{
 			Name:           "main_to_staging",
 			Ref:            "refs/heads/main",
-			ExpectedStage:  "staging",
+			ExpectedStages: []string{"staging"},
 			ExpectedResult: true,
 		},
 		{
 			Name:           "staging_to_canary",
 			Ref:            "refs/heads/staging",
-			ExpectedStage:  "canary",
+			ExpectedStages: []string{"canary"},
 			ExpectedResult: true,
 		},
 		{
 			Name:           "canary_to_production",
 			Ref:            "refs/heads/canary",
-			ExpectedStage:  "production",
+			ExpectedStages: []string{"production"},
 			ExpectedResult: true,
 		},
 		{
 			Name:           "invalid_stage",
 			Ref:            "refs/heads/feature",
-			ExpectedStage:  "",
+			ExpectedStages: nil,
 			ExpectedResult: false,
 		},
 		{
 			Name:           "invalid_next_stage",
 			Ref:            "refs/heads/production",
-			ExpectedStage:  "",
+			ExpectedStages: nil,
 			ExpectedResult: false,
 		},
 	}
@@ -144,13 +147,119 @@ func TestIsPromotableRef(t *testing.T) {
 	promoter := promotion.NewStagePromoter("test", []string{"main", "staging", "canary", "production"})
 	for _, tc := range testCases {
 		t.Run(tc.Name, func(t *testing.T) {
-			stage, result := promoter.IsPromotableRef(tc.Ref)
-			assert.Equal(t, tc.ExpectedStage, stage)
+			stages, result := promoter.IsPromotableRef(tc.Ref)
+			assert.Equal(t, tc.ExpectedStages, stages)
 			assert.Equal(t, tc.ExpectedResult, result)
 		})
 	}
 }
 
+func TestNewGraphPromoter(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		Definition      string
+		ExpectedStages  []string
+		ExpectedTargets map[string][]string
+		ExpectedError   bool
+	}{
+		{
+			Name:            "linear",
+			Definition:      "main, staging, production",
+			ExpectedStages:  []string{"main", "staging", "production"},
+			ExpectedTargets: map[string][]string{"main": {"staging"}, "staging": {"production"}},
+		},
+		{
+			Name:           "fan_out_and_in",
+			Definition:     "main > staging > canary-eu, canary-us > production",
+			ExpectedStages: []string{"main", "staging", "canary-eu", "canary-us", "production"},
+			ExpectedTargets: map[string][]string{
+				"main":      {"staging"},
+				"staging":   {"canary-eu", "canary-us"},
+				"canary-eu": {"production"},
+				"canary-us": {"production"},
+			},
+		},
+		{
+			Name:           "chains",
+			Definition:     "main→staging→production; staging>canary>production;",
+			ExpectedStages: []string{"main", "staging", "canary", "production"},
+			ExpectedTargets: map[string][]string{
+				"main":    {"staging"},
+				"staging": {"production", "canary"},
+				"canary":  {"production"},
+			},
+		},
+		{
+			Name:          "cycle",
+			Definition:    "main > staging > main",
+			ExpectedError: true,
+		},
+		{
+			Name:          "empty_level",
+			Definition:    "main > > staging",
+			ExpectedError: true,
+		},
+		{
+			Name:          "empty",
+			Definition:    " , ",
+			ExpectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			promoter, err := promotion.NewGraphPromoter("test", tc.Definition)
+			if tc.ExpectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.ExpectedStages, promoter.Stages)
+			assert.Equal(t, tc.ExpectedTargets, promoter.Targets)
+		})
+	}
+}
+
+func TestGraphPromoter(t *testing.T) {
+	promoter, err := promotion.NewGraphPromoter("test", "main > staging > canary-eu, canary-us > production")
+	require.NoError(t, err)
+
+	stages, ok := promoter.IsPromotableRef("refs/heads/staging")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"canary-eu", "canary-us"}, stages)
+	assert.Equal(t, []string{"canary-eu", "canary-us"}, promoter.PreviousStages("refs/heads/production"))
+	assert.Equal(t, []string{"main"}, promoter.PreviousStages("staging"))
+	assert.Empty(t, promoter.PreviousStages("main"))
+
+	pr := func(head, base string) *github.PullRequest {
+		return &github.PullRequest{Head: &github.PullRequestBranch{Ref: &head}, Base: &github.PullRequestBranch{Ref: &base}}
+	}
+	assert.True(t, promoter.IsPromotionRequest(pr("staging", "canary-us")))
+	assert.True(t, promoter.IsPromotionRequest(pr("canary-eu", "production")))
+	assert.False(t, promoter.IsPromotionRequest(pr("staging", "production")))
+	assert.False(t, promoter.IsPromotionRequest(pr("canary-eu", "canary-us")))
+
+	assert.Equal(t, 2, promoter.Level("canary-eu"))
+	assert.Equal(t, 2, promoter.Level("canary-us"))
+	assert.Equal(t, 3, promoter.Level("production"))
+	assert.Equal(t, -1, promoter.Level("feature"))
+	assert.Equal(t, 4, promoter.Levels())
+
+	assert.Equal(t, "main>staging;staging>canary-eu,canary-us;canary-eu>production;canary-us>production", promoter.String())
+	assert.Equal(t, "main,staging,production", promotion.NewStagePromoter("test", []string{"main", "staging", "production"}).String())
+
+	mermaid, err := promoter.Mermaid(pr("canary-eu", "production"), nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, mermaid, "staging --> canary-us")
+	assert.Contains(t, mermaid, "canary-us --> production")
+	assert.Contains(t, mermaid, "canary-eu --> pr")
+	assert.Contains(t, mermaid, "pr --- production")
+	assert.NotContains(t, mermaid, "canary-eu --> production")
+
+	_, err = promoter.Mermaid(pr("staging", "production"), nil, nil)
+	assert.Error(t, err)
+}
+
 func TestNewDynamicPromoter(t *testing.T) {
 	testCases := []struct {
 		Name           string
@@ -231,6 +340,29 @@ func TestNewDynamicPromoter(t *testing.T) {
 			},
 			PromoterKey: "gitops-promotion-path",
 		},
+		{
+			Name: "valid_dynamic_promoter_graph",
+			Properties: map[string]any{
+				"gitops-promotion-path": `main > staging > canary-eu, canary-us > production`,
+			},
+			PromoterKey:    "gitops-promotion-path",
+			ExpectedStages: []string{"main", "staging", "canary-eu", "canary-us", "production"},
+		},
+		{
+			Name: "valid_dynamic_promoter_multi_select_graph",
+			Properties: map[string]any{
+				"gitops-promotion-path": []any{"main>staging>canary-eu>production", "staging>canary-us>production"},
+			},
+			PromoterKey:    "gitops-promotion-path",
+			ExpectedStages: []string{"main", "staging", "canary-eu", "canary-us", "production"},
+		},
+		{
+			Name: "invalid_dynamic_promoter_graph",
+			Properties: map[string]any{
+				"gitops-promotion-path": `main > staging > main`,
+			},
+			PromoterKey: "gitops-promotion-path",
+		},
 		{
 			Name: "mismatched_promoter_key",
 			Properties: map[string]any{
@@ -251,3 +383,21 @@ func TestNewDynamicPromoter(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDefaultPromoter(t *testing.T) {
+	saved := config.Promotion
+	t.Cleanup(func() { config.Promotion = saved })
+	config.Promotion.DefaultStages = []string{"main", "staging", "production"}
+
+	config.Promotion.DefaultGraph = "main > staging > canary-eu, canary-us"
+	promoter := promotion.NewDefaultPromoter(helpers.NewNoopLogger())
+	assert.Equal(t, []string{"main", "staging", "canary-eu", "canary-us"}, promoter.Stages)
+
+	// Invalid default graphs fall back to the default stages, and are logged rather than silently ignored
+	var logs bytes.Buffer
+	config.Promotion.DefaultGraph = "main > staging > main"
+	promoter = promotion.NewDefaultPromoter(slog.New(slog.NewTextHandler(&logs, nil)))
+	assert.Equal(t, config.Promotion.DefaultStages, promoter.Stages)
+	assert.Contains(t, logs.String(), "level=ERROR")
+	assert.Contains(t, logs.String(), "invalid default promotion graph")
+}
@@ -0,0 +1,121 @@
+package promotion
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+)
+
+// Readiness is the outcome of the evaluation of the required checks of a head SHA.
+type Readiness struct {
+	// Missing lists the required checks not reported on the head SHA.
+	Missing []string
+	// Pending lists the required checks still running.
+	Pending []string
+	// Failing lists the required checks that did not succeed.
+	Failing []string
+}
+
+// Ready reports whether every required check succeeded.
+func (r Readiness) Ready() bool {
+	return len(r.Missing) == 0 && len(r.Pending) == 0 && len(r.Failing) == 0
+}
+
+// String summarises the required checks that are not green, e.g. "missing: lint; failing: test".
+func (r Readiness) String() string {
+	var parts []string
+	for _, group := range []struct {
+		name   string
+		checks []string
+	}{{"missing", r.Missing}, {"pending", r.Pending}, {"failing", r.Failing}} {
+		if len(group.checks) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", group.name, strings.Join(group.checks, ", ")))
+		}
+	}
+	if len(parts) == 0 {
+		return "all required checks passed"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// checkState is the state of a single check, reduced from a commit status or a check run.
+type checkState int
+
+const (
+	checkPending checkState = iota
+	checkPassed
+	checkFailed
+)
+
+// EvaluateReadiness compares the commit statuses and check runs reported on a head SHA with the required checks,
+// matched by status context or check run name. Only the latest report of each check is considered; a check reported
+// both as a commit status and as a check run must pass as both.
+func EvaluateReadiness(required []string, statuses []*github.RepoStatus, checkRuns []*github.CheckRun) Readiness {
+	states := make(map[string][]checkState, len(required))
+	for _, status := range latestStatuses(statuses) {
+		state := checkPending
+		switch status.GetState() {
+		case "success":
+			state = checkPassed
+		case "failure", "error":
+			state = checkFailed
+		}
+		states[status.GetContext()] = append(states[status.GetContext()], state)
+	}
+	for _, run := range latestCheckRuns(checkRuns) {
+		state := checkPending
+		if run.GetStatus() == "completed" {
+			state = checkFailed
+			// Neutral and skipped check runs satisfy required checks
+			if slices.Contains([]string{"success", "neutral", "skipped"}, run.GetConclusion()) {
+				state = checkPassed
+			}
+		}
+		states[run.GetName()] = append(states[run.GetName()], state)
+	}
+
+	var readiness Readiness
+	seen := make(map[string]bool, len(required))
+	for _, check := range required {
+		if seen[check] {
+			continue
+		}
+		seen[check] = true
+		reported, found := states[check]
+		switch {
+		case !found:
+			readiness.Missing = append(readiness.Missing, check)
+		case slices.Contains(reported, checkFailed):
+			readiness.Failing = append(readiness.Failing, check)
+		case slices.Contains(reported, checkPending):
+			readiness.Pending = append(readiness.Pending, check)
+		}
+	}
+	return readiness
+}
+
+// latestStatuses returns the most recently updated commit status of each context.
+func latestStatuses(statuses []*github.RepoStatus) map[string]*github.RepoStatus {
+	latest := make(map[string]*github.RepoStatus, len(statuses))
+	for _, status := range statuses {
+		if current, found := latest[status.GetContext()]; !found || status.GetUpdatedAt().After(current.GetUpdatedAt().Time) {
+			latest[status.GetContext()] = status
+		}
+	}
+	return latest
+}
+
+// latestCheckRuns returns the most recently started check run of each name, e.g. the re-run of a failed check run.
+func latestCheckRuns(checkRuns []*github.CheckRun) map[string]*github.CheckRun {
+	latest := make(map[string]*github.CheckRun, len(checkRuns))
+	for _, run := range checkRuns {
+		current, found := latest[run.GetName()]
+		if !found || run.GetStartedAt().After(current.GetStartedAt().Time) ||
+			(run.GetStartedAt().Equal(current.GetStartedAt()) && run.GetID() > current.GetID()) {
+			latest[run.GetName()] = run
+		}
+	}
+	return latest
+}
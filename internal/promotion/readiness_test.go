@@ -0,0 +1,83 @@
+package promotion_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateReadiness(t *testing.T) {
+	at := func(minutes int) *github.Timestamp {
+		return &github.Timestamp{Time: time.Date(2026, 1, 1, 0, minutes, 0, 0, time.UTC)}
+	}
+	status := func(context, state string, updated int) *github.RepoStatus {
+		return &github.RepoStatus{Context: new(context), State: new(state), UpdatedAt: at(updated)}
+	}
+	checkRun := func(name, status, conclusion string, started int) *github.CheckRun {
+		run := &github.CheckRun{Name: new(name), Status: new(status), StartedAt: at(started)}
+		if conclusion != "" {
+			run.Conclusion = new(conclusion)
+		}
+		return run
+	}
+
+	tests := []struct {
+		name      string
+		required  []string
+		statuses  []*github.RepoStatus
+		checkRuns []*github.CheckRun
+		want      promotion.Readiness
+	}{
+		{
+			name: "no required checks",
+		},
+		{
+			name:      "all green",
+			required:  []string{"ci/lint", "test", "docs"},
+			statuses:  []*github.RepoStatus{status("ci/lint", "success", 1)},
+			checkRuns: []*github.CheckRun{checkRun("test", "completed", "success", 1), checkRun("docs", "completed", "skipped", 1)},
+		},
+		{
+			name:      "missing, pending and failing",
+			required:  []string{"ci/lint", "test", "e2e", "build"},
+			statuses:  []*github.RepoStatus{status("ci/lint", "pending", 1)},
+			checkRuns: []*github.CheckRun{checkRun("test", "completed", "failure", 1), checkRun("build", "in_progress", "", 1)},
+			want: promotion.Readiness{
+				Missing: []string{"e2e"},
+				Pending: []string{"ci/lint", "build"},
+				Failing: []string{"test"},
+			},
+		},
+		{
+			name:      "latest report wins",
+			required:  []string{"ci/lint", "test"},
+			statuses:  []*github.RepoStatus{status("ci/lint", "success", 2), status("ci/lint", "failure", 1)},
+			checkRuns: []*github.CheckRun{checkRun("test", "completed", "failure", 1), checkRun("test", "completed", "success", 2)},
+		},
+		{
+			name:      "status and check run of the same name",
+			required:  []string{"test", "test"},
+			statuses:  []*github.RepoStatus{status("test", "error", 1)},
+			checkRuns: []*github.CheckRun{checkRun("test", "completed", "success", 1)},
+			want:      promotion.Readiness{Failing: []string{"test"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, promotion.EvaluateReadiness(tt.required, tt.statuses, tt.checkRuns))
+		})
+	}
+}
+
+func TestReadinessString(t *testing.T) {
+	ready := promotion.Readiness{}
+	assert.True(t, ready.Ready())
+	assert.Equal(t, "all required checks passed", ready.String())
+
+	notReady := promotion.Readiness{Missing: []string{"e2e", "docs"}, Failing: []string{"test"}}
+	assert.False(t, notReady.Ready())
+	assert.Equal(t, "missing: e2e, docs; failing: test", notReady.String())
+}
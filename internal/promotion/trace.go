@@ -0,0 +1,99 @@
+package promotion
+
+import (
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/models"
+)
+
+// SkipReason is a machine-readable reason for skipping the processing of an event.
+type SkipReason string
+
+const (
+	// SkipEventDisabled is reported when the event type is not enabled.
+	SkipEventDisabled SkipReason = "event-disabled"
+	// SkipUnprocessableState is reported when the event status, state or conclusion does not allow a promotion.
+	SkipUnprocessableState SkipReason = "unprocessable-state"
+	// SkipUnprocessableAction is reported when the event action does not allow a promotion.
+	SkipUnprocessableAction SkipReason = "unprocessable-action"
+	// SkipDraftPullRequest is reported for events on draft pull requests.
+	SkipDraftPullRequest SkipReason = "draft-pull-request"
+	// SkipNotPromotionBranch is reported for events on refs that are not part of the promotion path.
+	SkipNotPromotionBranch SkipReason = "not-promotion-branch"
+	// SkipNoPromotionRequest is reported when no open promotion request matches the event.
+	SkipNoPromotionRequest SkipReason = "no-promotion-request"
+	// SkipMissingHeadSHA is reported for events that do not resolve to a head SHA.
+	SkipMissingHeadSHA SkipReason = "missing-head-sha"
+	// SkipAlreadyPromoted is reported when the target ref already points to the head SHA.
+	SkipAlreadyPromoted SkipReason = "already-promoted"
+	// SkipOnHold is reported for promotion requests held with the hold command.
+	SkipOnHold SkipReason = "on-hold"
+	// SkipPermissionDenied is reported for commands issued by users lacking the role required by the stage.
+	SkipPermissionDenied SkipReason = "permission-denied"
+	// SkipNotDeployed is reported when the head SHA is not yet successfully deployed to the environment required by the stage.
+	SkipNotDeployed SkipReason = "not-deployed"
+	// SkipNotReady is reported when required checks of the head SHA are missing, pending or failing.
+	SkipNotReady SkipReason = "not-ready"
+	// SkipNotApproved is reported when the reviews of the promotion request do not satisfy the review policy of the stage.
+	SkipNotApproved SkipReason = "not-approved"
+	// SkipAwaitingParents is reported when the head SHA is not yet promoted into every other stage promoting into a join stage.
+	SkipAwaitingParents SkipReason = "awaiting-parents"
+	// SkipWorkflowFiltered is reported for workflow runs not matching the workflow run filter of the target stage.
+	SkipWorkflowFiltered SkipReason = "workflow-filtered"
+	// SkipSelfEvent is reported for events echoing the feedback of the app, or sent by ignored senders.
+	SkipSelfEvent SkipReason = "self-event"
+	// SkipRepositoryEvent is reported for repository and installation events fully handled by their event processor,
+	// with no promotion to feed back.
+	SkipRepositoryEvent SkipReason = "repository-event"
+)
+
+// Trace outcomes recorded for each processor.
+const (
+	// OutcomeProcessed is recorded when a processor completed and processing carries on.
+	OutcomeProcessed = "processed"
+	// OutcomeSkipped is recorded when a processor decided to skip the event.
+	OutcomeSkipped = "skipped"
+	// OutcomeFailed is recorded when a processor returned an error.
+	OutcomeFailed = "failed"
+)
+
+type skipDecision struct {
+	reason  SkipReason
+	message string
+}
+
+// Skip marks the event as skipped, recording the reason in the decision trace of the current processor.
+func (b *Bus) Skip(reason SkipReason, message string) {
+	b.EventStatus = Skipped
+	b.skip = &skipDecision{reason: reason, message: message}
+}
+
+// RecordStep appends the outcome of a processor to the decision trace.
+// The status is the EventStatus observed before the processor ran, used to tell skips decided by this processor apart.
+func (b *Bus) RecordStep(processor string, started time.Time, status EventStatus, err error) {
+	entry := models.TraceEntry{
+		Processor:  processor,
+		Outcome:    OutcomeProcessed,
+		DurationMs: float64(time.Since(started).Microseconds()) / 1000,
+	}
+	switch {
+	case err != nil:
+		entry.Outcome = OutcomeFailed
+		entry.Error = err.Error()
+	case b.skip != nil:
+		entry.Outcome = OutcomeSkipped
+		entry.Reason = string(b.skip.reason)
+		entry.Message = b.skip.message
+	case b.EventStatus == Skipped && status != Skipped:
+		entry.Outcome = OutcomeSkipped
+	}
+	b.skip = nil
+
+	if b.Context != nil {
+		entry.HeadRef = helpers.String(b.Context.HeadRef)
+		entry.BaseRef = helpers.String(b.Context.BaseRef)
+		entry.HeadSHA = helpers.String(b.Context.HeadSHA)
+	}
+	b.Trace = append(b.Trace, entry)
+}
@@ -0,0 +1,45 @@
+package promotion_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBusTrace(t *testing.T) {
+	headRef, headSHA := "main", "abc"
+	bus := &promotion.Bus{
+		EventStatus: promotion.Pending,
+		Context:     &promotion.Context{HeadRef: &headRef, HeadSHA: &headSHA},
+	}
+
+	bus.RecordStep("dynamic-promotion", time.Now(), bus.EventStatus, nil)
+
+	status := bus.EventStatus
+	bus.Skip(promotion.SkipUnprocessableState, `status state "pending"`)
+	bus.RecordStep("status", time.Now(), status, nil)
+
+	// Later processors of the phase do not inherit the skip decision
+	bus.RecordStep("s3-uploader", time.Now(), bus.EventStatus, nil)
+	bus.RecordStep("check-run", time.Now(), bus.EventStatus, errors.New("boom"))
+
+	require.Len(t, bus.Trace, 4)
+	assert.Equal(t, promotion.OutcomeProcessed, bus.Trace[0].Outcome)
+	assert.Equal(t, "main", bus.Trace[0].HeadRef)
+	assert.Equal(t, "abc", bus.Trace[0].HeadSHA)
+
+	assert.Equal(t, promotion.OutcomeSkipped, bus.Trace[1].Outcome)
+	assert.Equal(t, string(promotion.SkipUnprocessableState), bus.Trace[1].Reason)
+	assert.Equal(t, `status state "pending"`, bus.Trace[1].Message)
+	assert.Equal(t, promotion.Skipped, bus.EventStatus)
+
+	assert.Equal(t, promotion.OutcomeProcessed, bus.Trace[2].Outcome)
+	assert.Empty(t, bus.Trace[2].Reason)
+
+	assert.Equal(t, promotion.OutcomeFailed, bus.Trace[3].Outcome)
+	assert.Equal(t, "boom", bus.Trace[3].Error)
+}
@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+)
+
+// FromPaths reads the payloads of the given files and, recursively, of the files of the given directories, keeping
+// those matching the filter. Files named after the keys of the S3 uploader, e.g. synchronised from its bucket, carry
+// their event type, repository and archive time; eventType is required for other files, whose time is their
+// modification time. eventType, if set, overrides the event type of every file.
+func FromPaths(paths []string, eventType event.Type, filter Filter) ([]Payload, error) {
+	var payloads []Payload
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				if entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			payload, err := readFile(path, entry, eventType)
+			if err != nil {
+				return err
+			}
+			if filter.Match(payload) {
+				payloads = append(payloads, payload)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payloads from %s: %w", root, err)
+		}
+	}
+	return payloads, nil
+}
+
+// readFile reads the payload of the given file.
+func readFile(path string, entry fs.DirEntry, eventType event.Type) (Payload, error) {
+	info, err := entry.Info()
+	if err != nil {
+		return Payload{}, err
+	}
+	body, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return Payload{}, err
+	}
+
+	payload := Payload{Source: path, EventType: eventType, Time: info.ModTime(), Body: body}
+	if archivedAt, repository, keyEventType, ok := ParseKey(keyOf(path)); ok {
+		payload.Time, payload.Repository = archivedAt, repository
+		if payload.EventType == "" {
+			payload.EventType = keyEventType
+		}
+	}
+	if payload.EventType == "" {
+		return Payload{}, fmt.Errorf("%s: unknown event type of a payload not named after an S3 uploader key", path)
+	}
+	if payload.Repository == "" {
+		payload.Repository = repositoryOf(body)
+	}
+	return payload, nil
+}
+
+// keyOf returns the last three elements of the path, i.e. "<time>.<owner>", "<repository>" and "<event type>" if the
+// file is named after an S3 uploader key.
+func keyOf(path string) string {
+	elements := strings.Split(filepath.ToSlash(path), "/")
+	return strings.Join(elements[max(len(elements)-3, 0):], "/")
+}
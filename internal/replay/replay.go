@@ -0,0 +1,146 @@
+// Package replay provides the sources of the webhook payloads replayed offline through the promotion handler:
+// captured payload files, directories of payload files, and the objects archived by the S3 uploader.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+// Payload is a stored webhook payload along with the metadata needed to replay it.
+type Payload struct {
+	// Source identifies where the payload was read from, e.g. a file path or an S3 URI.
+	Source string
+	// EventType is the type of the webhook event carried by the payload.
+	EventType event.Type
+	// Repository is the full name of the repository of the event, e.g. "my-org/my-repo". Empty for installation events.
+	Repository string
+	// Time is when the payload was archived, or the modification time of its file.
+	Time time.Time
+	Body []byte
+}
+
+// Filter restricts the payloads to replay. Empty fields match any payload.
+type Filter struct {
+	// Repositories lists the full names of the repositories whose payloads are replayed, ignoring case.
+	Repositories []string
+	// EventTypes lists the event types of the payloads to replay.
+	EventTypes []string
+	// Since excludes the payloads archived before this time.
+	Since time.Time
+	// Until excludes the payloads archived after this time.
+	Until time.Time
+}
+
+// Match reports whether the payload matches the filter.
+func (f Filter) Match(p Payload) bool {
+	return f.matchRepository(p.Repository) && f.matchEventType(p.EventType) && f.matchTime(p.Time)
+}
+
+func (f Filter) matchRepository(repository string) bool {
+	return len(f.Repositories) == 0 || slices.ContainsFunc(f.Repositories, func(r string) bool { return strings.EqualFold(r, repository) })
+}
+
+func (f Filter) matchEventType(eventType event.Type) bool {
+	return len(f.EventTypes) == 0 || slices.Contains(f.EventTypes, string(eventType))
+}
+
+func (f Filter) matchTime(t time.Time) bool {
+	return (f.Since.IsZero() || !t.Before(f.Since)) && (f.Until.IsZero() || !t.After(f.Until))
+}
+
+// ParseKey parses the key of an object archived by the S3 uploader, formatted as "<RFC 3339 time>.<owner>/<repository>/<event type>".
+// It reports whether the key is in this format.
+func ParseKey(key string) (archivedAt time.Time, repository string, eventType event.Type, ok bool) {
+	// Keys are written in UTC, so the time always ends with a Z
+	timestamp, id, found := strings.Cut(key, "Z.")
+	if !found {
+		return time.Time{}, "", "", false
+	}
+	archivedAt, err := time.Parse(time.RFC3339Nano, timestamp+"Z")
+	if err != nil {
+		return time.Time{}, "", "", false
+	}
+	slash := strings.LastIndex(id, "/")
+	if slash <= 0 || slash == len(id)-1 || !strings.Contains(id[:slash], "/") {
+		return time.Time{}, "", "", false
+	}
+	return archivedAt, id[:slash], event.Type(id[slash+1:]), true
+}
+
+// Sort sorts the payloads chronologically, so that they are replayed in the order they were received.
+func Sort(payloads []Payload) {
+	slices.SortStableFunc(payloads, func(a, b Payload) int { return a.Time.Compare(b.Time) })
+}
+
+// Processor processes a webhook payload along with its headers, e.g. handler.Handler.Process.
+type Processor func(ctx context.Context, body []byte, headers map[string]string) (*promotion.Bus, error)
+
+// Result is the outcome of a replayed payload.
+type Result struct {
+	Source     string          `json:"source"`
+	EventType  string          `json:"eventType"`
+	Repository string          `json:"repository,omitempty"`
+	Time       time.Time       `json:"time"`
+	Status     string          `json:"status,omitempty"`
+	Response   models.Response `json:"response"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// Replay feeds the payloads to the processor in order, with a delivery ID derived from their content.
+// Failures do not stop the replay of the next payloads: they are reported in their result and joined in the returned error.
+func Replay(ctx context.Context, process Processor, payloads []Payload) ([]Result, error) {
+	results := make([]Result, 0, len(payloads))
+	var errs []error
+	for _, payload := range payloads {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		digest := sha256.Sum256(payload.Body)
+		headers := map[string]string{
+			strings.ToLower(github.EventTypeHeader):  string(payload.EventType),
+			strings.ToLower(github.DeliveryIDHeader): "replay-" + hex.EncodeToString(digest[:8]),
+			"content-type":                           "application/json",
+		}
+
+		result := Result{
+			Source:     payload.Source,
+			EventType:  string(payload.EventType),
+			Repository: payload.Repository,
+			Time:       payload.Time,
+		}
+		bus, err := process(ctx, payload.Body, headers)
+		if bus != nil {
+			result.Status = string(bus.EventStatus)
+			result.Response = bus.Response
+		}
+		if err != nil {
+			result.Error = err.Error()
+			errs = append(errs, fmt.Errorf("%s: %w", payload.Source, err))
+		}
+		results = append(results, result)
+	}
+	return results, errors.Join(errs...)
+}
+
+// repositoryOf returns the full name of the repository of the webhook payload, if any.
+func repositoryOf(body []byte) string {
+	var eventRepository models.EventRepository
+	if err := json.Unmarshal(body, &eventRepository); err != nil || eventRepository.Repository.FullName == nil {
+		return ""
+	}
+	return *eventRepository.Repository.FullName
+}
@@ -0,0 +1,137 @@
+package replay_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/isometry/gh-promotion-app/internal/replay"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pushBody = `{"ref":"refs/heads/main","repository":{"full_name":"my-org/my-repo"}}`
+
+func TestParseKey(t *testing.T) {
+	archivedAt, repository, eventType, ok := replay.ParseKey("2024-03-16T10:20:30.123456789Z.my-org/my-repo/push")
+	require.True(t, ok)
+	assert.Equal(t, time.Date(2024, 3, 16, 10, 20, 30, 123456789, time.UTC), archivedAt)
+	assert.Equal(t, "my-org/my-repo", repository)
+	assert.Equal(t, event.Push, eventType)
+
+	for _, key := range []string{
+		"push_event.json",
+		"2024-03-16T10:20:30Z.my-repo/push",
+		"2024-03-16T10:20:30Z.my-org/my-repo/",
+		"not-a-timeZ.my-org/my-repo/push",
+	} {
+		_, _, _, ok = replay.ParseKey(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	payload := replay.Payload{EventType: event.Push, Repository: "my-org/my-repo", Time: time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)}
+
+	assert.True(t, replay.Filter{}.Match(payload))
+	assert.True(t, replay.Filter{Repositories: []string{"My-Org/My-Repo"}, EventTypes: []string{"push"}}.Match(payload))
+	assert.False(t, replay.Filter{Repositories: []string{"my-org/other"}}.Match(payload))
+	assert.False(t, replay.Filter{EventTypes: []string{"pull_request"}}.Match(payload))
+	assert.True(t, replay.Filter{Since: payload.Time, Until: payload.Time}.Match(payload))
+	assert.False(t, replay.Filter{Since: payload.Time.Add(time.Second)}.Match(payload))
+	assert.False(t, replay.Filter{Until: payload.Time.Add(-time.Second)}.Match(payload))
+}
+
+func TestFromPaths(t *testing.T) {
+	dir := t.TempDir()
+	archived := filepath.Join(dir, "2024-03-16T10:20:30Z.my-org", "my-repo", "push")
+	require.NoError(t, os.MkdirAll(filepath.Dir(archived), 0o750))
+	require.NoError(t, os.WriteFile(archived, []byte(pushBody), 0o600))
+	captured := filepath.Join(dir, "push_event.json")
+	require.NoError(t, os.WriteFile(captured, []byte(`{"repository":{"full_name":"my-org/other"}}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte(`{}`), 0o600))
+
+	// Files not named after an S3 uploader key need an event type
+	_, err := replay.FromPaths([]string{dir}, "", replay.Filter{})
+	require.Error(t, err)
+
+	payloads, err := replay.FromPaths([]string{archived}, "", replay.Filter{})
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, event.Push, payloads[0].EventType)
+	assert.Equal(t, "my-org/my-repo", payloads[0].Repository)
+	assert.Equal(t, time.Date(2024, 3, 16, 10, 20, 30, 0, time.UTC), payloads[0].Time)
+
+	payloads, err = replay.FromPaths([]string{dir}, event.Push, replay.Filter{})
+	require.NoError(t, err)
+	assert.Len(t, payloads, 2)
+
+	payloads, err = replay.FromPaths([]string{dir}, event.Push, replay.Filter{Repositories: []string{"my-org/other"}})
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, captured, payloads[0].Source)
+}
+
+type objectStore map[string]string
+
+func (s objectStore) ListS3Objects(_ context.Context, _, prefix string) ([]string, error) {
+	var keys []string
+	for key := range s {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s objectStore) GetS3Object(_ context.Context, _, key string) ([]byte, error) {
+	return []byte(s[key]), nil
+}
+
+func TestFromS3(t *testing.T) {
+	objects := objectStore{
+		"2024-03-16T10:20:30Z.my-org/my-repo/push":         pushBody,
+		"2024-03-16T11:20:30Z.my-org/my-repo/pull_request": `{}`,
+		"2024-03-17T10:20:30Z.my-org/my-repo/push":         pushBody,
+		"2024-03-16-unrelated":                             `{}`,
+	}
+
+	payloads, err := replay.FromS3(context.Background(), objects, "bucket", "2024-03-16", replay.Filter{EventTypes: []string{"push"}})
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "s3://bucket/2024-03-16T10:20:30Z.my-org/my-repo/push", payloads[0].Source)
+	assert.Equal(t, pushBody, string(payloads[0].Body))
+}
+
+func TestReplay(t *testing.T) {
+	payloads := []replay.Payload{
+		{Source: "b", EventType: event.Push, Time: time.Unix(2, 0), Body: []byte(`{"n":2}`)},
+		{Source: "a", EventType: event.Push, Time: time.Unix(1, 0), Body: []byte(`{"n":1}`)},
+	}
+	replay.Sort(payloads)
+
+	var deliveries []string
+	process := func(_ context.Context, body []byte, headers map[string]string) (*promotion.Bus, error) {
+		assert.Equal(t, "push", headers["x-github-event"])
+		deliveries = append(deliveries, headers["x-github-delivery"])
+		if string(body) == `{"n":2}` {
+			return &promotion.Bus{EventStatus: promotion.Error}, errors.New("boom")
+		}
+		return &promotion.Bus{EventStatus: promotion.Success}, nil
+	}
+
+	results, err := replay.Replay(context.Background(), process, payloads)
+	require.ErrorContains(t, err, "b: boom")
+	require.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].Source)
+	assert.Equal(t, string(promotion.Success), results[0].Status)
+	assert.Equal(t, "boom", results[1].Error)
+	assert.NotEqual(t, deliveries[0], deliveries[1])
+	assert.True(t, strings.HasPrefix(deliveries[0], "replay-"))
+}
@@ -0,0 +1,44 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+)
+
+// ObjectStore is the subset of the AWS controller used to read the objects archived by the S3 uploader.
+type ObjectStore interface {
+	ListS3Objects(ctx context.Context, bucket, prefix string) ([]string, error)
+	GetS3Object(ctx context.Context, bucket, key string) ([]byte, error)
+}
+
+// FromS3 reads the payloads archived by the S3 uploader under the given prefix of the bucket, keeping those matching
+// the filter. Objects are filtered on their key before being downloaded; objects whose key is not in the format of the
+// S3 uploader are ignored. As keys start with their archive time, the prefix can narrow the listing to a day, e.g. "2024-03-16".
+func FromS3(ctx context.Context, objects ObjectStore, bucket, prefix string, filter Filter) ([]Payload, error) {
+	keys, err := objects.ListS3Objects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list archived payloads: %w", err)
+	}
+
+	var payloads []Payload
+	for _, key := range keys {
+		archivedAt, repository, eventType, ok := ParseKey(key)
+		if !ok {
+			continue
+		}
+		payload := Payload{
+			Source:     fmt.Sprintf("s3://%s/%s", bucket, key),
+			EventType:  eventType,
+			Repository: repository,
+			Time:       archivedAt,
+		}
+		if !filter.Match(payload) {
+			continue
+		}
+		if payload.Body, err = objects.GetS3Object(ctx, bucket, key); err != nil {
+			return nil, fmt.Errorf("failed to read archived payload %s: %w", payload.Source, err)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
@@ -0,0 +1,117 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/helpers"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+)
+
+var (
+	// ErrQueueFull is returned by Queue.Enqueue when the queue depth limit is reached.
+	ErrQueueFull = errors.New("work queue is full")
+	// ErrQueueClosed is returned by Queue.Enqueue once the queue has started draining.
+	ErrQueueClosed = errors.New("work queue is closed")
+)
+
+// Worker processes a single authenticated Bus.
+type Worker func(ctx context.Context, bus *promotion.Bus) (*promotion.Bus, error)
+
+// Queue is a bounded in-process work queue consumed by a fixed pool of workers.
+type Queue struct {
+	jobs       chan *promotion.Bus
+	worker     Worker
+	jobTimeout time.Duration
+	logger     *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewQueue starts a pool of workers consuming up to depth queued buses. Each job is bounded by jobTimeout, if positive.
+func NewQueue(workers, depth int, jobTimeout time.Duration, worker Worker, logger *slog.Logger) *Queue {
+	if logger == nil {
+		logger = helpers.NewNoopLogger()
+	}
+	q := &Queue{
+		jobs:       make(chan *promotion.Bus, max(depth, 0)),
+		worker:     worker,
+		jobTimeout: jobTimeout,
+		logger:     logger,
+	}
+	for i := range max(workers, 1) {
+		q.wg.Add(1)
+		go q.work(i)
+	}
+	return q
+}
+
+// Enqueue hands the bus over to the worker pool without blocking.
+// It returns ErrQueueFull when no capacity is left and ErrQueueClosed once Shutdown was called.
+func (q *Queue) Enqueue(bus *promotion.Bus) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.jobs <- bus:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting new work and waits for the queued work to complete, or for the context to be done.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work(id int) {
+	defer q.wg.Done()
+	logger := q.logger.With(slog.Int("worker", id))
+	for bus := range q.jobs {
+		q.process(logger, bus)
+	}
+}
+
+func (q *Queue) process(logger *slog.Logger, bus *promotion.Bus) {
+	ctx := context.Background()
+	if q.jobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.jobTimeout)
+		defer cancel()
+	}
+
+	logger = logger.With(slog.String("event", string(bus.EventType)))
+	logger.Debug("processing queued event...")
+	bus, err := q.worker(ctx, bus)
+	if err != nil {
+		logger.Error("failed to process queued event", slog.Any("error", err))
+		return
+	}
+	logger.Info("processed queued event", slog.Any("status", bus.EventStatus), slog.Int("statusCode", bus.Response.StatusCode))
+}
@@ -0,0 +1,38 @@
+package runtime_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/isometry/gh-promotion-app/internal/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	started, release := make(chan struct{}, 3), make(chan struct{})
+	var processed atomic.Int32
+	worker := func(_ context.Context, bus *promotion.Bus) (*promotion.Bus, error) {
+		started <- struct{}{}
+		<-release
+		processed.Add(1)
+		return bus, nil
+	}
+
+	q := runtime.NewQueue(1, 2, time.Second, worker, nil)
+
+	// The single worker picks up the first job, the queue holds the next two
+	require.NoError(t, q.Enqueue(&promotion.Bus{}))
+	<-started
+	require.NoError(t, q.Enqueue(&promotion.Bus{}))
+	require.NoError(t, q.Enqueue(&promotion.Bus{}))
+	assert.ErrorIs(t, q.Enqueue(&promotion.Bus{}), runtime.ErrQueueFull)
+
+	close(release)
+	require.NoError(t, q.Shutdown(context.Background()))
+	assert.Equal(t, int32(3), processed.Load())
+	assert.ErrorIs(t, q.Enqueue(&promotion.Bus{}), runtime.ErrQueueClosed)
+}
@@ -4,16 +4,20 @@
 package runtime
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/isometry/gh-promotion-app/internal/handler"
 	"github.com/isometry/gh-promotion-app/internal/helpers"
 	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
 )
 
 // Option defines a function type used to configure a Runtime instance during initialization.
@@ -26,10 +30,33 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithRequestTimeout bounds the processing of each request handled in service mode.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(r *Runtime) {
+		r.requestTimeout = timeout
+	}
+}
+
+// WithAsync enables asynchronous processing in service mode: authenticated requests are answered with 202 Accepted
+// and handed over to a pool of workers consuming a queue bounded by depth. Each job is bounded by jobTimeout.
+func WithAsync(workers, depth int, jobTimeout time.Duration) Option {
+	return func(r *Runtime) {
+		r.async = &asyncOptions{workers: workers, depth: depth, jobTimeout: jobTimeout}
+	}
+}
+
+type asyncOptions struct {
+	workers, depth int
+	jobTimeout     time.Duration
+}
+
 // Runtime represents the execution context integrating the handler and logger for processing runtime events.
 type Runtime struct {
 	*handler.Handler
-	logger *slog.Logger
+	logger         *slog.Logger
+	requestTimeout time.Duration
+	async          *asyncOptions
+	queue          *Queue
 }
 
 // NewRuntime creates a new runtime instance.
@@ -41,11 +68,25 @@ func NewRuntime(handler *handler.Handler, opts ...Option) *Runtime {
 	if _inst.logger == nil {
 		_inst.logger = helpers.NewNoopLogger()
 	}
+	if _inst.async != nil {
+		_inst.queue = NewQueue(_inst.async.workers, _inst.async.depth, _inst.async.jobTimeout,
+			_inst.Run, _inst.logger.With("component", "queue"))
+	}
 	return _inst
 }
 
+// Shutdown drains the asynchronous work queue, if any, waiting for the queued work to complete or the context to be done.
+func (r *Runtime) Shutdown(ctx context.Context) error {
+	if r.queue == nil {
+		return nil
+	}
+	r.logger.Info("draining work queue...")
+	return r.queue.Shutdown(ctx)
+}
+
 // Lambda is the entrypoint function when the `--mode lambda` flag is set.
-func (r *Runtime) Lambda(req models.Request) (response any, err error) {
+// The context carries the Lambda invocation deadline.
+func (r *Runtime) Lambda(ctx context.Context, req models.Request) (response any, err error) {
 	r.logger.Info("received request", slog.Any("request", req))
 
 	// Lower-case incoming headers for compatibility purposes
@@ -54,26 +95,37 @@ func (r *Runtime) Lambda(req models.Request) (response any, err error) {
 		headers[k] = strings.ToLower(v)
 	}
 
-	bus, err := r.Process([]byte(req.Body), headers)
+	bus, err := r.Process(ctx, []byte(req.Body), headers)
 	if err != nil {
-		return nil, err
+		// Answer with the status code of the error class rather than failing the invocation:
+		// only retryable failures are answered with 5xx
+		r.logger.Error("failed to process request", slog.Any("error", err), slog.Any("kind", promotion.Classify(err)))
+		if bus == nil {
+			bus = new(promotion.Bus)
+		}
+		bus.Response.Body = err.Error()
+		bus.Response.StatusCode = promotion.StatusCode(err)
+		err = nil
 	}
 
+	// The body carries the same JSON document as in service mode, including the decision trace
+	body := string(helpers.MarshalResponse(bus.Response, nil))
+
 	payloadType := r.GetLambdaPayloadType()
 	switch payloadType {
 	case "api-gateway-v1":
 		return events.APIGatewayProxyResponse{
-			Body:       bus.Response.Body,
+			Body:       body,
 			StatusCode: bus.Response.StatusCode,
 		}, err
 	case "api-gateway-v2":
 		return events.APIGatewayV2HTTPResponse{
-			Body:       bus.Response.Body,
+			Body:       body,
 			StatusCode: bus.Response.StatusCode,
 		}, err
 	case "lambda-url":
 		return events.LambdaFunctionURLResponse{
-			Body:       bus.Response.Body,
+			Body:       body,
 			StatusCode: bus.Response.StatusCode,
 		}, err
 	default:
@@ -82,17 +134,41 @@ func (r *Runtime) Lambda(req models.Request) (response any, err error) {
 }
 
 // LambdaForEvent is the entrypoint function when the `--mode lambda-event` flag is set.
-func (r *Runtime) LambdaForEvent(event map[string]any) (any, error) {
+// The context carries the Lambda invocation deadline.
+func (r *Runtime) LambdaForEvent(ctx context.Context, event map[string]any) (any, error) {
 	r.logger.Info("received event", slog.Any("event", event))
 
-	bus, err := r.ProcessEvent(event)
+	bus, err := r.ProcessEvent(ctx, event)
 	if err != nil {
-		return nil, err
+		// Failing the invocation has the event retried, which is only worthwhile for retryable failures
+		if bus == nil || promotion.IsRetryable(err) {
+			return nil, err
+		}
+		r.logger.Error("failed to process event", slog.Any("error", err), slog.Any("kind", promotion.Classify(err)))
+		bus.Response.Body = err.Error()
+		bus.Response.StatusCode = promotion.StatusCode(err)
+		return bus.Response, nil
 	}
 
 	return bus.Response, err
 }
 
+// LambdaForSchedule is the entrypoint function when the `--mode lambda-schedule` flag is set, triggered by an EventBridge
+// schedule to reconcile the promotions missed with lost webhooks. The context carries the Lambda invocation deadline.
+func (r *Runtime) LambdaForSchedule(ctx context.Context, event events.EventBridgeEvent) (any, error) {
+	r.logger.Info("received scheduled event", slog.String("id", event.ID), slog.Time("time", event.Time))
+
+	report, err := r.Reconcile(ctx)
+	if err != nil {
+		// The next scheduled run retries the failed reconciliations, so the invocation only fails if nothing was reconciled
+		if report.Repositories == 0 {
+			return nil, err
+		}
+		r.logger.Error("failed to reconcile some repositories", slog.Any("error", err))
+	}
+	return report, nil
+}
+
 // Service is the entrypoint function when the `--mode service` flag is set.
 func (r *Runtime) Service(rw http.ResponseWriter, req *http.Request) {
 	switch req.Method {
@@ -104,8 +180,8 @@ func (r *Runtime) Service(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	r.logger = r.logger.With(slog.Any("requestor", req.RemoteAddr), slog.Any("method", req.Method), slog.Any("path", req.URL.String()), slog.Any("headers", req.Header))
-	r.logger.Debug("processing HTTP request...")
+	logger := r.logger.With(slog.Any("requestor", req.RemoteAddr), slog.Any("method", req.Method), slog.Any("path", req.URL.String()), slog.Any("headers", req.Header))
+	logger.Debug("processing HTTP request...")
 
 	headers := make(map[string]string, len(req.Header))
 	for k, v := range req.Header {
@@ -114,15 +190,61 @@ func (r *Runtime) Service(rw http.ResponseWriter, req *http.Request) {
 
 	body, err := io.ReadAll(req.Body)
 	if err != nil {
-		r.logger.Error("failed to read request body", slog.Any("error", err))
+		logger.Error("failed to read request body", slog.Any("error", err))
 		helpers.RespondHTTP(rw, models.Response{StatusCode: http.StatusInternalServerError}, err)
 		return
 	}
-	bus, err := r.Process(body, headers)
+	ctx := req.Context()
+	if r.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.requestTimeout)
+		defer cancel()
+	}
+	if r.queue != nil {
+		r.enqueue(ctx, rw, logger, body, headers)
+		return
+	}
+
+	bus, err := r.Process(ctx, body, headers)
 	if err != nil {
-		r.logger.Error("failed to process request", slog.Any("error", err))
-		helpers.RespondHTTP(rw, models.Response{StatusCode: http.StatusInternalServerError}, err)
+		logger.Error("failed to process request", slog.Any("error", err), slog.Any("kind", promotion.Classify(err)))
+		helpers.RespondHTTP(rw, errorResponse(bus, err), err)
 		return
 	}
 	helpers.RespondHTTP(rw, bus.Response, err)
 }
+
+// errorResponse returns the response to a failed request, answered with the status code of the error class so that
+// only retryable failures are answered with 5xx. The bus may be nil, e.g. when the request failed authentication.
+func errorResponse(bus *promotion.Bus, err error) models.Response {
+	response := models.Response{StatusCode: promotion.StatusCode(err)}
+	if bus != nil {
+		response.Trace = bus.Trace
+	}
+	return response
+}
+
+// enqueue authenticates the request synchronously and hands the resulting Bus over to the worker pool.
+func (r *Runtime) enqueue(ctx context.Context, rw http.ResponseWriter, logger *slog.Logger, body []byte, headers map[string]string) {
+	bus, err := r.Authenticate(ctx, body, headers)
+	if err != nil {
+		logger.Error("failed to authenticate request", slog.Any("error", err), slog.Any("kind", promotion.Classify(err)))
+		helpers.RespondHTTP(rw, errorResponse(bus, err), err)
+		return
+	}
+
+	switch err = r.queue.Enqueue(bus); {
+	case err == nil:
+		logger.Debug("request queued")
+		helpers.RespondHTTP(rw, models.Response{Body: "Queued", StatusCode: http.StatusAccepted}, nil)
+	case errors.Is(err, ErrQueueFull), errors.Is(err, ErrQueueClosed):
+		logger.Warn("rejecting request", slog.Any("error", err))
+		helpers.RespondHTTP(rw, models.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Headers:    map[string]string{"Retry-After": "10"},
+		}, err)
+	default:
+		logger.Error("failed to queue request", slog.Any("error", err))
+		helpers.RespondHTTP(rw, models.Response{StatusCode: http.StatusInternalServerError}, err)
+	}
+}
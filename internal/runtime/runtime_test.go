@@ -0,0 +1,117 @@
+package runtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/go-github/v88/github"
+	"github.com/isometry/gh-promotion-app/internal/config"
+	"github.com/isometry/gh-promotion-app/internal/controllers/github/event"
+	"github.com/isometry/gh-promotion-app/internal/handler"
+	"github.com/isometry/gh-promotion-app/internal/handler/processor"
+	"github.com/isometry/gh-promotion-app/internal/models"
+	"github.com/isometry/gh-promotion-app/internal/promotion"
+	"github.com/isometry/gh-promotion-app/internal/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAsyncAuthFailure(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Headers        map[string]string
+		ExpectedStatus int
+	}{
+		{
+			Name:           "missing_event_type",
+			Headers:        map[string]string{github.DeliveryIDHeader: "delivery"},
+			ExpectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "missing_delivery_id",
+			Headers:        map[string]string{github.EventTypeHeader: "push"},
+			ExpectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			Name:           "unsupported_event_type",
+			Headers:        map[string]string{github.EventTypeHeader: "unsupported", github.DeliveryIDHeader: "delivery"},
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	h, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"))
+	require.NoError(t, err)
+	r := runtime.NewRuntime(h, runtime.WithAsync(1, 1, time.Second))
+	t.Cleanup(func() { _ = r.Shutdown(context.Background()) })
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+			for k, v := range tc.Headers {
+				req.Header.Set(k, v)
+			}
+			rw := httptest.NewRecorder()
+
+			r.Service(rw, req)
+
+			assert.Equal(t, tc.ExpectedStatus, rw.Code)
+			assert.Contains(t, rw.Body.String(), `"error"`)
+		})
+	}
+}
+
+// blockingProcessor blocks until its phase is cancelled, as a processor stalled on an unresponsive upstream API would.
+type blockingProcessor struct{}
+
+func (blockingProcessor) Name() string { return "blocking" }
+
+func (blockingProcessor) SetLogger(*slog.Logger) {}
+
+func (blockingProcessor) Process(ctx context.Context, req any) (*promotion.Bus, error) {
+	<-ctx.Done()
+	return req.(*promotion.Bus), ctx.Err()
+}
+
+func TestLambdaPhaseTimeout(t *testing.T) {
+	processor.Register("test-blocking", func(processor.Dependencies, ...processor.Option) processor.Processor {
+		return blockingProcessor{}
+	})
+	saved := config.Pipeline
+	t.Cleanup(func() { config.Pipeline = saved })
+	config.Pipeline.Pre = []config.PipelineStep{{Name: "test-blocking"}}
+	config.Pipeline.Events = map[string][]config.PipelineStep{string(event.Push): {}}
+	config.Pipeline.Post = nil
+	config.Pipeline.Feedback = nil
+	config.Pipeline.Timeouts.Pre = 10 * time.Millisecond
+
+	h, err := handler.NewPromotionHandler(handler.WithAuthMode("token"), handler.WithToken("token"),
+		handler.WithoutSignatureValidation(), handler.WithLambdaPayloadType("lambda-url"))
+	require.NoError(t, err)
+	r := runtime.NewRuntime(h)
+	body := `{"installation": {"id": 42}, "repository": {"name": "repo", "full_name": "owner/repo", "owner": {"login": "owner"}}}`
+
+	// Requests are answered with a retryable status code rather than failing the invocation
+	response, err := r.Lambda(context.Background(), models.Request{
+		Body:    body,
+		Headers: map[string]string{"x-github-event": "push", "x-github-delivery": "delivery"},
+	})
+	require.NoError(t, err)
+	require.IsType(t, events.LambdaFunctionURLResponse{}, response)
+	assert.Equal(t, http.StatusServiceUnavailable, response.(events.LambdaFunctionURLResponse).StatusCode)
+	assert.Contains(t, response.(events.LambdaFunctionURLResponse).Body, promotion.ErrBudgetExhausted.Error())
+
+	// Events fail the invocation, so that they are retried
+	var detail map[string]any
+	require.NoError(t, json.Unmarshal([]byte(body), &detail))
+	_, err = r.LambdaForEvent(context.Background(), map[string]any{"id": "delivery", "detail-type": "push", "detail": detail})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, promotion.ErrBudgetExhausted)
+	assert.True(t, promotion.IsRetryable(err))
+}